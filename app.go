@@ -2,22 +2,43 @@ package main
 
 import (
 	"context"
+	"log/slog"
 
 	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 
+	"MMDContent/internal/entities"
+	"MMDContent/internal/search"
 	"MMDContent/internal/storage"
 )
 
 type App struct {
-	ctx           context.Context
-	modelsStorage *storage.Models
-	stagesStorage *storage.Stages
+	ctx            context.Context
+	modelsStorage  *storage.Models
+	stagesStorage  *storage.Stages
+	motionsStorage *storage.Motions
+	vectors        *storage.Vectors
+	usage          *storage.Usage
+	index          *search.VectorIndex
+	queries        *storage.QueryEmbeddings
 }
 
-func NewApp(modelsStorage *storage.Models, stagesStorage *storage.Stages) *App {
+func NewApp(
+	modelsStorage *storage.Models,
+	stagesStorage *storage.Stages,
+	motionsStorage *storage.Motions,
+	vectors *storage.Vectors,
+	usage *storage.Usage,
+	index *search.VectorIndex,
+	queries *storage.QueryEmbeddings,
+) *App {
 	return &App{
-		modelsStorage: modelsStorage,
-		stagesStorage: stagesStorage,
+		modelsStorage:  modelsStorage,
+		stagesStorage:  stagesStorage,
+		motionsStorage: motionsStorage,
+		vectors:        vectors,
+		usage:          usage,
+		index:          index,
+		queries:        queries,
 	}
 }
 
@@ -26,10 +47,54 @@ func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
+// GetStartupReport returns what happened to every catalog when the app started,
+// e.g. a corrupt catalog file restored from a backup, and the other data files that
+// were recovered from corruption
+func (a *App) GetStartupReport() entities.StartupReport {
+	report := entities.StartupReport{
+		Catalogs: []entities.CatalogStartup{
+			a.modelsStorage.Startup(),
+			a.stagesStorage.Startup(),
+			a.motionsStorage.Startup(),
+		},
+		Files: make([]entities.FileStartup, 0),
+	}
+
+	if recovery, ok := a.vectors.Startup(); ok {
+		report.Files = append(report.Files, recovery)
+	}
+	if recovery, ok := a.usage.Startup(); ok {
+		report.Files = append(report.Files, recovery)
+	}
+
+	return report
+}
+
 // Quit closes the app
 func (a *App) Quit() {
 	wailsruntime.Quit(a.ctx)
 }
 
+// emit sends an event to the frontend, it is a no-op until the app has started
+func (a *App) emit(event string, data any) {
+	if a.ctx == nil {
+		return
+	}
+
+	wailsruntime.EventsEmit(a.ctx, event, data)
+}
+
 // shutdown is called when the app is closing
-func (a *App) shutdown(ctx context.Context) {}
+func (a *App) shutdown(ctx context.Context) {
+	if err := a.index.Save(); err != nil {
+		slog.Error("error saving vector index", "error", err)
+	}
+
+	if err := a.queries.Save(); err != nil {
+		slog.Error("error saving query embedding cache", "error", err)
+	}
+
+	if err := a.vectors.Close(); err != nil {
+		slog.Error("error closing vectors", "error", err)
+	}
+}
@@ -2,6 +2,7 @@ import { useState } from "react";
 import "./App.css";
 import { Sidebar } from "@/components/shared/Sidebar";
 import { MainContent } from "@/components/shared/MainContent";
+import { StartupReport } from "@/components/shared/StartupReport";
 
 export type ViewState = {
 	view: string;
@@ -57,6 +58,7 @@ export default function App() {
 				onShowDetail={handleShowDetail}
 				onBackFromDetail={handleBackFromDetail}
 			/>
+			<StartupReport />
 		</div>
 	);
 }
@@ -26,10 +26,16 @@ interface ModelsGridProps {
 	) => void;
 }
 
+// Only the most relevant results are shown, weak matches are left out
+const SEARCH_LIMIT = 100;
+const MIN_SEARCH_SCORE = 0.2;
+
 export function ModelsGrid({ onShowDetail }: ModelsGridProps) {
 	const [paginatedData, setPaginatedData] =
 		useState<entities.Pagination_MMDContent_internal_entities_Model_ | null>(null);
-	const [searchResults, setSearchResults] = useState<entities.Model[] | null>(null);
+	const [searchResults, setSearchResults] = useState<
+		entities.SearchResult_MMDContent_internal_entities_Model_[] | null
+	>(null);
 	const [loading, setLoading] = useState(true);
 	const [searching, setSearching] = useState(false);
 	const [page, setPage] = useState(1);
@@ -39,7 +45,7 @@ export function ModelsGrid({ onShowDetail }: ModelsGridProps) {
 	const loadModels = async () => {
 		setLoading(true);
 		try {
-			const data = await GetModels(page, perPage);
+			const data = await GetModels(page, perPage, "");
 			setPaginatedData(data);
 		} catch (error) {
 			console.error("Error loading models:", error);
@@ -56,7 +62,7 @@ export function ModelsGrid({ onShowDetail }: ModelsGridProps) {
 
 		setSearching(true);
 		try {
-			const results = await SearchModels(searchQuery, 1000); // Limit to top 1000 results
+			const results = await SearchModels(searchQuery, SEARCH_LIMIT, MIN_SEARCH_SCORE);
 			setSearchResults(results);
 		} catch (error) {
 			console.error("Error searching models:", error);
@@ -105,7 +111,9 @@ export function ModelsGrid({ onShowDetail }: ModelsGridProps) {
 	};
 
 	// Use search results if searching, otherwise use paginated data
-	const displayData = searchResults || paginatedData?.data || [];
+	const displayData = searchResults
+		? searchResults.map((result) => result.item)
+		: paginatedData?.data || [];
 	const isSearching = searchResults !== null;
 
 	if (loading && !paginatedData && !searchResults) {
@@ -204,13 +212,16 @@ export function ModelsGrid({ onShowDetail }: ModelsGridProps) {
 				</div>
 			) : (
 				<div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 xl:grid-cols-4 gap-4">
-					{displayData.map((model) => (
+					{displayData.map((model, index) => (
 						<MMDContentCard
 							key={model.id}
 							id={model.id}
 							name={model.name}
 							screenshots={model.screenshots}
 							description={model.description}
+							score={searchResults?.[index].score}
+							snippets={searchResults?.[index].snippets}
+							passage={searchResults?.[index].passage}
 							onClick={() => onShowDetail("model", model)}
 						/>
 					))}
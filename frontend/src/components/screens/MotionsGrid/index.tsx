@@ -27,10 +27,16 @@ interface MotionsGridProps {
 	) => void;
 }
 
+// Only the most relevant results are shown, weak matches are left out
+const SEARCH_LIMIT = 100;
+const MIN_SEARCH_SCORE = 0.2;
+
 export function MotionsGrid({ onShowDetail }: MotionsGridProps) {
 	const [paginatedData, setPaginatedData] =
 		useState<entities.Pagination_MMDContent_internal_entities_Motion_ | null>(null);
-	const [searchResults, setSearchResults] = useState<entities.Motion[] | null>(null);
+	const [searchResults, setSearchResults] = useState<
+		entities.SearchResult_MMDContent_internal_entities_Motion_[] | null
+	>(null);
 	const [loading, setLoading] = useState(true);
 	const [searching, setSearching] = useState(false);
 	const [page, setPage] = useState(1);
@@ -40,7 +46,7 @@ export function MotionsGrid({ onShowDetail }: MotionsGridProps) {
 	const loadMotions = async () => {
 		setLoading(true);
 		try {
-			const data = await GetMotions(page, perPage);
+			const data = await GetMotions(page, perPage, "");
 			setPaginatedData(data);
 		} catch (error) {
 			console.error("Error loading motions:", error);
@@ -57,7 +63,7 @@ export function MotionsGrid({ onShowDetail }: MotionsGridProps) {
 
 		setSearching(true);
 		try {
-			const results = await SearchMotions(searchQuery, 1000); // Limit to top 1000 results
+			const results = await SearchMotions(searchQuery, SEARCH_LIMIT, MIN_SEARCH_SCORE);
 			setSearchResults(results);
 		} catch (error) {
 			console.error("Error searching motions:", error);
@@ -106,7 +112,9 @@ export function MotionsGrid({ onShowDetail }: MotionsGridProps) {
 	};
 
 	// Use search results if searching, otherwise use paginated data
-	const displayData = searchResults || paginatedData?.data || [];
+	const displayData = searchResults
+		? searchResults.map((result) => result.item)
+		: paginatedData?.data || [];
 	const isSearching = searchResults !== null;
 
 	if (loading && !paginatedData && !searchResults) {
@@ -205,7 +213,7 @@ export function MotionsGrid({ onShowDetail }: MotionsGridProps) {
 				</div>
 			) : (
 				<div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 xl:grid-cols-4 gap-4">
-					{displayData.map((motion) => (
+					{displayData.map((motion, index) => (
 						<MMDContentCard
 							key={motion.id}
 							id={motion.id}
@@ -213,6 +221,9 @@ export function MotionsGrid({ onShowDetail }: MotionsGridProps) {
 							screenshots={motion.screenshots}
 							video={motion.video}
 							description={motion.description}
+							score={searchResults?.[index].score}
+							snippets={searchResults?.[index].snippets}
+							passage={searchResults?.[index].passage}
 							onClick={() => onShowDetail("motion", motion)}
 						/>
 					))}
@@ -26,10 +26,16 @@ interface StagesGridProps {
 	) => void;
 }
 
+// Only the most relevant results are shown, weak matches are left out
+const SEARCH_LIMIT = 100;
+const MIN_SEARCH_SCORE = 0.2;
+
 export function StagesGrid({ onShowDetail }: StagesGridProps) {
 	const [paginatedData, setPaginatedData] =
 		useState<entities.Pagination_MMDContent_internal_entities_Stage_ | null>(null);
-	const [searchResults, setSearchResults] = useState<entities.Stage[] | null>(null);
+	const [searchResults, setSearchResults] = useState<
+		entities.SearchResult_MMDContent_internal_entities_Stage_[] | null
+	>(null);
 	const [loading, setLoading] = useState(true);
 	const [searching, setSearching] = useState(false);
 	const [page, setPage] = useState(1);
@@ -39,7 +45,7 @@ export function StagesGrid({ onShowDetail }: StagesGridProps) {
 	const loadStages = async () => {
 		setLoading(true);
 		try {
-			const data = await GetStages(page, perPage);
+			const data = await GetStages(page, perPage, "");
 			setPaginatedData(data);
 		} catch (error) {
 			console.error("Error loading stages:", error);
@@ -56,7 +62,7 @@ export function StagesGrid({ onShowDetail }: StagesGridProps) {
 
 		setSearching(true);
 		try {
-			const results = await SearchStages(searchQuery, 1000); // Limit to top 1000 results
+			const results = await SearchStages(searchQuery, SEARCH_LIMIT, MIN_SEARCH_SCORE);
 			setSearchResults(results);
 		} catch (error) {
 			console.error("Error searching stages:", error);
@@ -105,7 +111,9 @@ export function StagesGrid({ onShowDetail }: StagesGridProps) {
 	};
 
 	// Use search results if searching, otherwise use paginated data
-	const displayData = searchResults || paginatedData?.data || [];
+	const displayData = searchResults
+		? searchResults.map((result) => result.item)
+		: paginatedData?.data || [];
 	const isSearching = searchResults !== null;
 
 	if (loading && !paginatedData && !searchResults) {
@@ -204,13 +212,16 @@ export function StagesGrid({ onShowDetail }: StagesGridProps) {
 				</div>
 			) : (
 				<div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 xl:grid-cols-4 gap-4">
-					{displayData.map((stage) => (
+					{displayData.map((stage, index) => (
 						<MMDContentCard
 							key={stage.id}
 							id={stage.id}
 							name={stage.name}
 							screenshots={stage.screenshots}
 							description={stage.description}
+							score={searchResults?.[index].score}
+							snippets={searchResults?.[index].snippets}
+							passage={searchResults?.[index].passage}
 							onClick={() => onShowDetail("stage", stage)}
 						/>
 					))}
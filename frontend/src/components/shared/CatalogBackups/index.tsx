@@ -0,0 +1,70 @@
+import { useEffect, useState } from "react";
+import { GetBackups, RestoreBackup } from "../../../../wailsjs/go/handlers/Backups";
+import { entities } from "../../../../wailsjs/go/models";
+import { Button } from "@/components/ui/button";
+
+function describeRestore(restore: entities.BackupRestore) {
+	const added = restore.sync.added?.length ?? 0;
+	const updated = restore.sync.updated?.length ?? 0;
+	const removed = restore.sync.removed?.length ?? 0;
+
+	return `Restored ${restore.backup.name}, then synced with the folder: ${added} added, ${updated} updated, ${removed} removed.`;
+}
+
+// CatalogBackups lists the backups of a catalog file and restores one of them. The catalog
+// replaced is backed up first, so a restore can be undone by restoring that backup.
+export function CatalogBackups({ type }: { type: string }) {
+	const [backups, setBackups] = useState<entities.CatalogBackup[]>([]);
+	const [restoring, setRestoring] = useState("");
+	const [message, setMessage] = useState("");
+
+	const loadBackups = () => {
+		GetBackups(type)
+			.then(setBackups)
+			.catch((error) => {
+				console.error("Error loading backups:", error);
+			});
+	};
+
+	useEffect(loadBackups, [type]);
+
+	const handleRestore = (name: string) => {
+		setRestoring(name);
+		RestoreBackup(type, name)
+			.then((restore) => {
+				setMessage(describeRestore(restore));
+				loadBackups();
+			})
+			.catch((error) => {
+				setMessage(`Error restoring ${name}: ${error}`);
+			})
+			.finally(() => setRestoring(""));
+	};
+
+	if (backups.length === 0) {
+		return <p className="text-muted-foreground">No backups of this catalog.</p>;
+	}
+
+	return (
+		<div className="space-y-1">
+			<ul className="max-h-40 space-y-1 overflow-y-auto">
+				{backups.map((backup) => (
+					<li key={backup.name} className="flex items-center justify-between gap-2">
+						<span className="text-muted-foreground">
+							{new Date(backup.time).toLocaleString()} ({Math.ceil(backup.size / 1024)} KB)
+						</span>
+						<Button
+							size="sm"
+							variant="outline"
+							disabled={restoring !== ""}
+							onClick={() => handleRestore(backup.name)}
+						>
+							{restoring === backup.name ? "Restoring..." : "Restore"}
+						</Button>
+					</li>
+				))}
+			</ul>
+			{message && <p>{message}</p>}
+		</div>
+	);
+}
@@ -1,5 +1,6 @@
 import { useState, useEffect } from "react";
 import { GetImageAsBase64, GetVideoAsBase64 } from "../../../../wailsjs/go/handlers/Images";
+import { entities } from "../../../../wailsjs/go/models";
 import { Card, CardContent } from "@/components/ui/card";
 import { Button } from "@/components/ui/button";
 import { ChevronLeft, ChevronRight } from "lucide-react";
@@ -10,6 +11,11 @@ interface MMDContentCardProps {
 	screenshots: string[] | null;
 	video?: string[] | null;
 	description: string;
+	// Relevance from 0 to 1 and matching passages, set for search results
+	score?: number;
+	snippets?: entities.Snippet[] | null;
+	// Part of a long description that matched the search best
+	passage?: string;
 	onClick?: () => void;
 }
 
@@ -19,6 +25,9 @@ export function MMDContentCard({
 	screenshots,
 	video,
 	description,
+	score,
+	snippets,
+	passage,
 	onClick,
 }: MMDContentCardProps) {
 	const [currentImageIndex, setCurrentImageIndex] = useState(0);
@@ -122,6 +131,7 @@ export function MMDContentCard({
 	};
 
 	const currentImage = loadedImages.get(currentImageIndex);
+	const snippet = snippets?.[0];
 
 	return (
 		<Card
@@ -183,6 +193,13 @@ export function MMDContentCard({
 						ID: {id}
 					</div>
 
+					{/* Relevance Badge */}
+					{score !== undefined && (
+						<div className="absolute bottom-2 right-2 bg-black/70 text-white text-xs px-2 py-1 rounded">
+							{Math.round(score * 100)}% match
+						</div>
+					)}
+
 					{/* Media Counter */}
 					{hasVideo && normalizedVideo.length > 1 && (
 						<div className="absolute top-2 right-2 bg-black/70 text-white text-xs px-2 py-1 rounded">
@@ -244,7 +261,19 @@ export function MMDContentCard({
 						{name}
 					</h3>
 					<p className="text-xs text-muted-foreground mt-1 line-clamp-2">
-						{description || "No description available"}
+						{snippet
+							? snippet.parts.map((part, index) =>
+									part.match ? (
+										<mark key={index} className="bg-yellow-200 text-foreground rounded-sm">
+											{part.text}
+										</mark>
+									) : (
+										<span key={index}>{part.text}</span>
+									)
+								)
+							: passage
+								? `…${passage}…`
+								: description || "No description available"}
 					</p>
 					<div className="mt-2 flex items-center gap-2 text-xs text-muted-foreground">
 						{hasVideo && (
@@ -1,16 +1,37 @@
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import {
 	LayoutDashboard,
 	Box,
 	Layers,
 	Settings,
 	Sparkles,
+	X,
 	Zap,
 } from "lucide-react";
 import { Avatar, AvatarFallback } from "@/components/ui/avatar";
 import { Badge } from "@/components/ui/badge";
 import { Button } from "@/components/ui/button";
-import { GenerateAll } from "../../../../wailsjs/go/handlers/Embeddings";
+import { Cancel, GenerateAll } from "../../../../wailsjs/go/handlers/Embeddings";
+import { GetUsage } from "../../../../wailsjs/go/handlers/Usage";
+import { entities } from "../../../../wailsjs/go/models";
+import { EventsOn } from "../../../../wailsjs/runtime/runtime";
+
+interface EmbeddingProgress {
+	type: string;
+	total: number;
+	done: number;
+	failed: number;
+	elapsedSeconds: number;
+	etaSeconds: number;
+}
+
+function formatDuration(seconds: number) {
+	if (seconds < 60) {
+		return `${Math.ceil(seconds)}s`;
+	}
+	const minutes = Math.floor(seconds / 60);
+	return `${minutes}m ${Math.ceil(seconds % 60)}s`;
+}
 
 interface SidebarProps {
 	currentView: string;
@@ -27,14 +48,45 @@ const menuItems = [
 export function Sidebar({ currentView, onViewChange }: SidebarProps) {
 	const [generatingEmbeddings, setGeneratingEmbeddings] = useState(false);
 	const [embeddingStatus, setEmbeddingStatus] = useState<string | null>(null);
+	const [progress, setProgress] = useState<EmbeddingProgress | null>(null);
+	const [usage, setUsage] = useState<entities.UsageSummary | null>(null);
+
+	const loadUsage = async () => {
+		try {
+			setUsage(await GetUsage());
+		} catch (error) {
+			console.error("Error loading embedding usage:", error);
+		}
+	};
+
+	useEffect(() => {
+		loadUsage();
+		return EventsOn("embeddings:progress", (data: EmbeddingProgress) => {
+			setProgress(data);
+		});
+	}, []);
 
 	const handleGenerateEmbeddings = async () => {
 		setGeneratingEmbeddings(true);
+		setProgress(null);
 		setEmbeddingStatus("Generating AI embeddings...");
 
 		try {
-			await GenerateAll();
-			setEmbeddingStatus("✓ Embeddings generated successfully!");
+			const results = await GenerateAll();
+			const generated = results.reduce((sum, r) => sum + r.generated, 0);
+			const failed = results.reduce((sum, r) => sum + r.failed, 0);
+			const cancelled = results.some((r) => r.cancelled);
+			const errored = results.filter((r) => r.error);
+
+			if (errored.length > 0) {
+				throw new Error(errored.map((r) => `${r.type}: ${r.error}`).join(", "));
+			}
+
+			setEmbeddingStatus(
+				cancelled
+					? `✓ Cancelled, ${generated} embeddings saved`
+					: `✓ ${generated} embeddings generated${failed > 0 ? `, ${failed} failed` : ""}`,
+			);
 
 			// Clear success message after 5 seconds
 			setTimeout(() => {
@@ -50,9 +102,16 @@ export function Sidebar({ currentView, onViewChange }: SidebarProps) {
 			}, 10000);
 		} finally {
 			setGeneratingEmbeddings(false);
+			setProgress(null);
+			loadUsage();
 		}
 	};
 
+	const handleCancelEmbeddings = async () => {
+		setEmbeddingStatus("Cancelling, saving completed embeddings...");
+		await Cancel();
+	};
+
 	return (
 		<div className="w-64 h-screen bg-white border-r border-border flex flex-col">
 			{/* Logo */}
@@ -106,11 +165,27 @@ export function Sidebar({ currentView, onViewChange }: SidebarProps) {
 								{generatingEmbeddings ? "Generating..." : "Generate AI Search"}
 							</div>
 							<div className="text-xs text-muted-foreground">
-								{generatingEmbeddings ? "This may take a few minutes" : "Enable semantic search"}
+								{generatingEmbeddings
+									? progress
+										? `${progress.done + progress.failed}/${progress.total} · ETA ${formatDuration(progress.etaSeconds)}`
+										: "Preparing..."
+									: "Enable semantic search"}
 							</div>
 						</div>
 					</Button>
 
+					{generatingEmbeddings && (
+						<Button
+							onClick={handleCancelEmbeddings}
+							variant="ghost"
+							size="sm"
+							className="w-full justify-start gap-3 text-muted-foreground"
+						>
+							<X className="w-4 h-4" />
+							Cancel
+						</Button>
+					)}
+
 					{/* Status Message */}
 					{embeddingStatus && (
 						<div
@@ -125,6 +200,20 @@ export function Sidebar({ currentView, onViewChange }: SidebarProps) {
 							{embeddingStatus}
 						</div>
 					)}
+
+					{/* Spend of the month */}
+					{usage && (
+						<div
+							className={`px-3 text-xs ${
+								usage.budgetExceeded ? "text-red-700" : "text-muted-foreground"
+							}`}
+						>
+							This month: ${usage.cost.toFixed(2)}
+							{usage.budget > 0 && ` of $${usage.budget.toFixed(2)}`} ·{" "}
+							{usage.tokens.toLocaleString()} tokens
+							{usage.budgetExceeded && " · budget spent"}
+						</div>
+					)}
 				</div>
 			</nav>
 		</div>
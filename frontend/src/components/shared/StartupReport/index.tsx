@@ -0,0 +1,107 @@
+import { useEffect, useState } from "react";
+import { GetStartupReport } from "../../../../wailsjs/go/main/App";
+import { entities } from "../../../../wailsjs/go/models";
+import {
+	Dialog,
+	DialogContent,
+	DialogDescription,
+	DialogFooter,
+	DialogHeader,
+	DialogTitle,
+} from "@/components/ui/dialog";
+import { Button } from "@/components/ui/button";
+import { CatalogBackups } from "@/components/shared/CatalogBackups";
+
+function describeRecovery(catalog: entities.CatalogStartup) {
+	const added = catalog.sync.added?.length ?? 0;
+
+	switch (catalog.recovery) {
+		case "created":
+			return `${catalog.file} did not exist and was created with the ${added} items found in the folder.`;
+		case "restored":
+			return `${catalog.file} was corrupt and was restored from the backup ${catalog.backup}.`;
+		case "rebuilt":
+			return `${catalog.file} was corrupt and had no valid backup, it was rebuilt from the ${added} items found in the folder.`;
+		default:
+			return "";
+	}
+}
+
+function describeMissingFolder(catalog: entities.CatalogStartup) {
+	return `The folder ${catalog.missingFolder} was not found, ${catalog.file} was kept as it was. Refresh once the folder is back.`;
+}
+
+// StartupReport tells the user about catalog files that were created or recovered at launch,
+// about content folders that were missing and about other data files recovered from corruption.
+// A recovered catalog may be restored from another of its backups right from the dialog.
+export function StartupReport() {
+	const [catalogs, setCatalogs] = useState<entities.CatalogStartup[]>([]);
+	const [files, setFiles] = useState<entities.FileStartup[]>([]);
+	const [open, setOpen] = useState(false);
+
+	useEffect(() => {
+		GetStartupReport()
+			.then((report) => {
+				// A first launch creates every catalog, that is not worth a dialog
+				const recovered = report.catalogs.filter(
+					(c) => (c.recovery && c.recovery !== "created") || c.missingFolder,
+				);
+				const files = report.files ?? [];
+				if (recovered.length > 0 || files.length > 0) {
+					setCatalogs(report.catalogs.filter((c) => c.recovery || c.missingFolder));
+					setFiles(files);
+					setOpen(true);
+				}
+			})
+			.catch((error) => {
+				console.error("Error loading startup report:", error);
+			});
+	}, []);
+
+	return (
+		<Dialog open={open} onOpenChange={setOpen}>
+			<DialogContent>
+				<DialogHeader>
+					<DialogTitle>Catalogs at startup</DialogTitle>
+					<DialogDescription>
+						Some data files or catalog folders could not be loaded.
+					</DialogDescription>
+				</DialogHeader>
+				<ul className="space-y-3 text-sm">
+					{catalogs.map((catalog) => (
+						<li key={catalog.type}>
+							{catalog.recovery && <p>{describeRecovery(catalog)}</p>}
+							{catalog.missingFolder && <p>{describeMissingFolder(catalog)}</p>}
+							{catalog.error && (
+								<p className="text-muted-foreground">Error: {catalog.error}</p>
+							)}
+							{catalog.corruptFile && (
+								<p className="text-muted-foreground">
+									The corrupt file was kept as {catalog.corruptFile}.
+								</p>
+							)}
+							{catalog.recovery && catalog.recovery !== "created" && (
+								<CatalogBackups type={catalog.type} />
+							)}
+						</li>
+					))}
+					{files.map((file) => (
+						<li key={file.file}>
+							<p>
+								{file.file} was corrupt, what could be read from it was kept. Lost:{" "}
+								{file.lost}.
+							</p>
+							<p className="text-muted-foreground">Error: {file.error}</p>
+							<p className="text-muted-foreground">
+								A copy of the corrupt file was kept as {file.corruptFile}.
+							</p>
+						</li>
+					))}
+				</ul>
+				<DialogFooter>
+					<Button onClick={() => setOpen(false)}>OK</Button>
+				</DialogFooter>
+			</DialogContent>
+		</Dialog>
+	);
+}
@@ -1,8 +1,15 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {entities} from '../models';
 
-export function GenerateAll():Promise<void>;
+export function Cancel():Promise<void>;
 
-export function GenerateModelsEmbeddings():Promise<void>;
+export function GenerateAll():Promise<Array<entities.EmbeddingResult>>;
 
-export function GenerateStagesEmbeddings():Promise<void>;
+export function GenerateEmbeddings(arg1:string):Promise<entities.EmbeddingResult>;
+
+export function GetUnfinishedRun():Promise<entities.EmbeddingRun>;
+
+export function IsRunning():Promise<boolean>;
+
+export function Resume():Promise<Array<entities.EmbeddingResult>>;
@@ -2,14 +2,26 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function Cancel() {
+  return window['go']['handlers']['Embeddings']['Cancel']();
+}
+
 export function GenerateAll() {
   return window['go']['handlers']['Embeddings']['GenerateAll']();
 }
 
-export function GenerateModelsEmbeddings() {
-  return window['go']['handlers']['Embeddings']['GenerateModelsEmbeddings']();
+export function GenerateEmbeddings(arg1) {
+  return window['go']['handlers']['Embeddings']['GenerateEmbeddings'](arg1);
+}
+
+export function GetUnfinishedRun() {
+  return window['go']['handlers']['Embeddings']['GetUnfinishedRun']();
+}
+
+export function IsRunning() {
+  return window['go']['handlers']['Embeddings']['IsRunning']();
 }
 
-export function GenerateStagesEmbeddings() {
-  return window['go']['handlers']['Embeddings']['GenerateStagesEmbeddings']();
+export function Resume() {
+  return window['go']['handlers']['Embeddings']['Resume']();
 }
@@ -2,10 +2,20 @@
 // This file is automatically generated. DO NOT EDIT
 import {entities} from '../models';
 
+export function ExportModels(arg1:string):Promise<Array<entities.Model>>;
+
 export function GetAllModels():Promise<Array<entities.Model>>;
 
-export function GetModels(arg1:number,arg2:number):Promise<entities.Pagination_MMDContent_internal_entities_Model_>;
+export function GetModels(arg1:number,arg2:number,arg3:string):Promise<entities.Pagination_MMDContent_internal_entities_Model_>;
+
+export function HybridSearchModels(arg1:string,arg2:number,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Model_>>;
+
+export function KeywordSearchModels(arg1:string,arg2:number,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Model_>>;
+
+export function ModelsSimilarTo(arg1:string,arg2:string,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Model_>>;
+
+export function RefreshModelsData(arg1:boolean):Promise<entities.SyncReport>;
 
-export function RefreshModelsData():Promise<void>;
+export function SearchModels(arg1:string,arg2:number,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Model_>>;
 
-export function SearchModels(arg1:string,arg2:number):Promise<Array<entities.Model>>;
+export function SimilarModels(arg1:string,arg2:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Model_>>;
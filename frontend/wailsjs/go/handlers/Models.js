@@ -2,18 +2,38 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ExportModels(arg1) {
+  return window['go']['handlers']['Models']['ExportModels'](arg1);
+}
+
 export function GetAllModels() {
   return window['go']['handlers']['Models']['GetAllModels']();
 }
 
-export function GetModels(arg1, arg2) {
-  return window['go']['handlers']['Models']['GetModels'](arg1, arg2);
+export function GetModels(arg1, arg2, arg3) {
+  return window['go']['handlers']['Models']['GetModels'](arg1, arg2, arg3);
+}
+
+export function HybridSearchModels(arg1, arg2, arg3) {
+  return window['go']['handlers']['Models']['HybridSearchModels'](arg1, arg2, arg3);
+}
+
+export function KeywordSearchModels(arg1, arg2, arg3) {
+  return window['go']['handlers']['Models']['KeywordSearchModels'](arg1, arg2, arg3);
+}
+
+export function ModelsSimilarTo(arg1, arg2, arg3) {
+  return window['go']['handlers']['Models']['ModelsSimilarTo'](arg1, arg2, arg3);
+}
+
+export function RefreshModelsData(arg1) {
+  return window['go']['handlers']['Models']['RefreshModelsData'](arg1);
 }
 
-export function RefreshModelsData() {
-  return window['go']['handlers']['Models']['RefreshModelsData']();
+export function SearchModels(arg1, arg2, arg3) {
+  return window['go']['handlers']['Models']['SearchModels'](arg1, arg2, arg3);
 }
 
-export function SearchModels(arg1, arg2) {
-  return window['go']['handlers']['Models']['SearchModels'](arg1, arg2);
+export function SimilarModels(arg1, arg2) {
+  return window['go']['handlers']['Models']['SimilarModels'](arg1, arg2);
 }
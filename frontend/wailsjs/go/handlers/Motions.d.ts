@@ -2,10 +2,20 @@
 // This file is automatically generated. DO NOT EDIT
 import {entities} from '../models';
 
+export function ExportMotions(arg1:string):Promise<Array<entities.Motion>>;
+
 export function GetAllMotions():Promise<Array<entities.Motion>>;
 
-export function GetMotions(arg1:number,arg2:number):Promise<entities.Pagination_MMDContent_internal_entities_Motion_>;
+export function GetMotions(arg1:number,arg2:number,arg3:string):Promise<entities.Pagination_MMDContent_internal_entities_Motion_>;
+
+export function HybridSearchMotions(arg1:string,arg2:number,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Motion_>>;
+
+export function KeywordSearchMotions(arg1:string,arg2:number,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Motion_>>;
+
+export function MotionsSimilarTo(arg1:string,arg2:string,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Motion_>>;
+
+export function RefreshMotionsData(arg1:boolean):Promise<entities.SyncReport>;
 
-export function RefreshMotionsData():Promise<void>;
+export function SearchMotions(arg1:string,arg2:number,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Motion_>>;
 
-export function SearchMotions(arg1:string,arg2:number):Promise<Array<entities.Motion>>;
+export function SimilarMotions(arg1:string,arg2:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Motion_>>;
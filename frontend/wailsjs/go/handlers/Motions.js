@@ -2,18 +2,38 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ExportMotions(arg1) {
+  return window['go']['handlers']['Motions']['ExportMotions'](arg1);
+}
+
 export function GetAllMotions() {
   return window['go']['handlers']['Motions']['GetAllMotions']();
 }
 
-export function GetMotions(arg1, arg2) {
-  return window['go']['handlers']['Motions']['GetMotions'](arg1, arg2);
+export function GetMotions(arg1, arg2, arg3) {
+  return window['go']['handlers']['Motions']['GetMotions'](arg1, arg2, arg3);
+}
+
+export function HybridSearchMotions(arg1, arg2, arg3) {
+  return window['go']['handlers']['Motions']['HybridSearchMotions'](arg1, arg2, arg3);
+}
+
+export function KeywordSearchMotions(arg1, arg2, arg3) {
+  return window['go']['handlers']['Motions']['KeywordSearchMotions'](arg1, arg2, arg3);
+}
+
+export function MotionsSimilarTo(arg1, arg2, arg3) {
+  return window['go']['handlers']['Motions']['MotionsSimilarTo'](arg1, arg2, arg3);
+}
+
+export function RefreshMotionsData(arg1) {
+  return window['go']['handlers']['Motions']['RefreshMotionsData'](arg1);
 }
 
-export function RefreshMotionsData() {
-  return window['go']['handlers']['Motions']['RefreshMotionsData']();
+export function SearchMotions(arg1, arg2, arg3) {
+  return window['go']['handlers']['Motions']['SearchMotions'](arg1, arg2, arg3);
 }
 
-export function SearchMotions(arg1, arg2) {
-  return window['go']['handlers']['Motions']['SearchMotions'](arg1, arg2);
+export function SimilarMotions(arg1, arg2) {
+  return window['go']['handlers']['Motions']['SimilarMotions'](arg1, arg2);
 }
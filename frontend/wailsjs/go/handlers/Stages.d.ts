@@ -2,10 +2,20 @@
 // This file is automatically generated. DO NOT EDIT
 import {entities} from '../models';
 
+export function ExportStages(arg1:string):Promise<Array<entities.Stage>>;
+
 export function GetAllStages():Promise<Array<entities.Stage>>;
 
-export function GetStages(arg1:number,arg2:number):Promise<entities.Pagination_MMDContent_internal_entities_Stage_>;
+export function GetStages(arg1:number,arg2:number,arg3:string):Promise<entities.Pagination_MMDContent_internal_entities_Stage_>;
+
+export function HybridSearchStages(arg1:string,arg2:number,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Stage_>>;
+
+export function KeywordSearchStages(arg1:string,arg2:number,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Stage_>>;
+
+export function RefreshStagesData(arg1:boolean):Promise<entities.SyncReport>;
+
+export function SearchStages(arg1:string,arg2:number,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Stage_>>;
 
-export function RefreshStagesData():Promise<void>;
+export function SimilarStages(arg1:string,arg2:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Stage_>>;
 
-export function SearchStages(arg1:string,arg2:number):Promise<Array<entities.Stage>>;
+export function StagesSimilarTo(arg1:string,arg2:string,arg3:number):Promise<Array<entities.SearchResult_MMDContent_internal_entities_Stage_>>;
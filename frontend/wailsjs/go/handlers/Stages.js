@@ -2,18 +2,38 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ExportStages(arg1) {
+  return window['go']['handlers']['Stages']['ExportStages'](arg1);
+}
+
 export function GetAllStages() {
   return window['go']['handlers']['Stages']['GetAllStages']();
 }
 
-export function GetStages(arg1, arg2) {
-  return window['go']['handlers']['Stages']['GetStages'](arg1, arg2);
+export function GetStages(arg1, arg2, arg3) {
+  return window['go']['handlers']['Stages']['GetStages'](arg1, arg2, arg3);
+}
+
+export function HybridSearchStages(arg1, arg2, arg3) {
+  return window['go']['handlers']['Stages']['HybridSearchStages'](arg1, arg2, arg3);
+}
+
+export function KeywordSearchStages(arg1, arg2, arg3) {
+  return window['go']['handlers']['Stages']['KeywordSearchStages'](arg1, arg2, arg3);
+}
+
+export function RefreshStagesData(arg1) {
+  return window['go']['handlers']['Stages']['RefreshStagesData'](arg1);
+}
+
+export function SearchStages(arg1, arg2, arg3) {
+  return window['go']['handlers']['Stages']['SearchStages'](arg1, arg2, arg3);
 }
 
-export function RefreshStagesData() {
-  return window['go']['handlers']['Stages']['RefreshStagesData']();
+export function SimilarStages(arg1, arg2) {
+  return window['go']['handlers']['Stages']['SimilarStages'](arg1, arg2);
 }
 
-export function SearchStages(arg1, arg2) {
-  return window['go']['handlers']['Stages']['SearchStages'](arg1, arg2);
+export function StagesSimilarTo(arg1, arg2, arg3) {
+  return window['go']['handlers']['Stages']['StagesSimilarTo'](arg1, arg2, arg3);
 }
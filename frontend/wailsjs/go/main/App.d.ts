@@ -1,4 +1,7 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {entities} from '../models';
+
+export function GetStartupReport():Promise<entities.StartupReport>;
 
 export function Quit():Promise<void>;
@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function GetStartupReport() {
+  return window['go']['main']['App']['GetStartupReport']();
+}
+
 export function Quit() {
   return window['go']['main']['App']['Quit']();
 }
@@ -1,12 +1,75 @@
 export namespace entities {
 	
+	export class EmbeddingResult {
+	    type: string;
+	    total: number;
+	    generated: number;
+	    skipped: number;
+	    stale: number;
+	    failed: number;
+	    cancelled: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EmbeddingResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.total = source["total"];
+	        this.generated = source["generated"];
+	        this.skipped = source["skipped"];
+	        this.stale = source["stale"];
+	        this.failed = source["failed"];
+	        this.cancelled = source["cancelled"];
+	        this.error = source["error"];
+	    }
+	}
+	export class EmbeddingRun {
+	    // Go type: time
+	    startedAt: any;
+	    // Go type: time
+	    updatedAt: any;
+	    types: string[];
+	    completed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EmbeddingRun(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	        this.types = source["types"];
+	        this.completed = source["completed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Model {
 	    id: string;
 	    name: string;
 	    screenshots: string[];
 	    description: string;
 	    originalPath: string;
-	    embedding?: number[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Model(source);
@@ -19,7 +82,6 @@ export namespace entities {
 	        this.screenshots = source["screenshots"];
 	        this.description = source["description"];
 	        this.originalPath = source["originalPath"];
-	        this.embedding = source["embedding"];
 	    }
 	}
 	export class Motion {
@@ -29,7 +91,6 @@ export namespace entities {
 	    video: string[];
 	    description: string;
 	    originalPath: string;
-	    embedding?: number[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Motion(source);
@@ -43,7 +104,6 @@ export namespace entities {
 	        this.video = source["video"];
 	        this.description = source["description"];
 	        this.originalPath = source["originalPath"];
-	        this.embedding = source["embedding"];
 	    }
 	}
 	export class Pagination_MMDContent_internal_entities_Model_ {
@@ -128,7 +188,6 @@ export namespace entities {
 	    screenshots: string[];
 	    description: string;
 	    originalPath: string;
-	    embedding?: number[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Stage(source);
@@ -141,7 +200,6 @@ export namespace entities {
 	        this.screenshots = source["screenshots"];
 	        this.description = source["description"];
 	        this.originalPath = source["originalPath"];
-	        this.embedding = source["embedding"];
 	    }
 	}
 	export class Pagination_MMDContent_internal_entities_Stage_ {
@@ -183,5 +241,593 @@ export namespace entities {
 		}
 	}
 
+	export class SnippetPart {
+	    text: string;
+	    match: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnippetPart(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.text = source["text"];
+	        this.match = source["match"];
+	    }
+	}
+	export class Snippet {
+	    parts: SnippetPart[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Snippet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.parts = this.convertValues(source["parts"], SnippetPart);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchResult_MMDContent_internal_entities_Model_ {
+	    item: Model;
+	    score: number;
+	    rank: number;
+	    snippets: Snippet[];
+	    passage?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResult_MMDContent_internal_entities_Model_(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.item = this.convertValues(source["item"], Model);
+	        this.score = source["score"];
+	        this.rank = source["rank"];
+	        this.snippets = this.convertValues(source["snippets"], Snippet);
+	        this.passage = source["passage"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchResult_MMDContent_internal_entities_Motion_ {
+	    item: Motion;
+	    score: number;
+	    rank: number;
+	    snippets: Snippet[];
+	    passage?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResult_MMDContent_internal_entities_Motion_(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.item = this.convertValues(source["item"], Motion);
+	        this.score = source["score"];
+	        this.rank = source["rank"];
+	        this.snippets = this.convertValues(source["snippets"], Snippet);
+	        this.passage = source["passage"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchResult_MMDContent_internal_entities_Stage_ {
+	    item: Stage;
+	    score: number;
+	    rank: number;
+	    snippets: Snippet[];
+	    passage?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResult_MMDContent_internal_entities_Stage_(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.item = this.convertValues(source["item"], Stage);
+	        this.score = source["score"];
+	        this.rank = source["rank"];
+	        this.snippets = this.convertValues(source["snippets"], Snippet);
+	        this.passage = source["passage"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UnifiedResult {
+	    type: string;
+	    model?: Model;
+	    stage?: Stage;
+	    motion?: Motion;
+	    score: number;
+	    rank: number;
+	    snippets: Snippet[];
+	    passage?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UnifiedResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.model = this.convertValues(source["model"], Model);
+	        this.stage = this.convertValues(source["stage"], Stage);
+	        this.motion = this.convertValues(source["motion"], Motion);
+	        this.score = source["score"];
+	        this.rank = source["rank"];
+	        this.snippets = this.convertValues(source["snippets"], Snippet);
+	        this.passage = source["passage"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UnifiedSearchResults {
+	    results: UnifiedResult[];
+	    counts: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new UnifiedSearchResults(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.results = this.convertValues(source["results"], UnifiedResult);
+	        this.counts = source["counts"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ModelUsage {
+	    model: string;
+	    tokens: number;
+	    cost: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModelUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.model = source["model"];
+	        this.tokens = source["tokens"];
+	        this.cost = source["cost"];
+	    }
+	}
+	export class DailyUsage {
+	    date: string;
+	    tokens: number;
+	    cost: number;
+	    models: ModelUsage[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DailyUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.tokens = source["tokens"];
+	        this.cost = source["cost"];
+	        this.models = this.convertValues(source["models"], ModelUsage);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RunUsage {
+	    // Go type: time
+	    startedAt: any;
+	    // Go type: time
+	    updatedAt: any;
+	    tokens: number;
+	    cost: number;
+	    models: ModelUsage[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RunUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	        this.tokens = source["tokens"];
+	        this.cost = source["cost"];
+	        this.models = this.convertValues(source["models"], ModelUsage);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UsageSummary {
+	    month: string;
+	    tokens: number;
+	    cost: number;
+	    budget: number;
+	    remaining: number;
+	    budgetExceeded: boolean;
+	    days: DailyUsage[];
+	    runs: RunUsage[];
+	
+	    static createFrom(source: any = {}) {
+	        return new UsageSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.month = source["month"];
+	        this.tokens = source["tokens"];
+	        this.cost = source["cost"];
+	        this.budget = source["budget"];
+	        this.remaining = source["remaining"];
+	        this.budgetExceeded = source["budgetExceeded"];
+	        this.days = this.convertValues(source["days"], DailyUsage);
+	        this.runs = this.convertValues(source["runs"], RunUsage);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SyncRename {
+	    from: string;
+	    to: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncRename(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	    }
+	}
+	export class SyncReport {
+	    type: string;
+	    added: string[];
+	    updated: string[];
+	    removed: string[];
+	    renamed: SyncRename[];
+	    held: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.added = source["added"];
+	        this.updated = source["updated"];
+	        this.removed = source["removed"];
+	        this.renamed = this.convertValues(source["renamed"], SyncRename);
+	        this.held = source["held"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CatalogStartup {
+	    type: string;
+	    file: string;
+	    recovery?: string;
+	    backup?: string;
+	    corruptFile?: string;
+	    error?: string;
+	    missingFolder?: string;
+	    sync: SyncReport;
+	
+	    static createFrom(source: any = {}) {
+	        return new CatalogStartup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.file = source["file"];
+	        this.recovery = source["recovery"];
+	        this.backup = source["backup"];
+	        this.corruptFile = source["corruptFile"];
+	        this.error = source["error"];
+	        this.missingFolder = source["missingFolder"];
+	        this.sync = this.convertValues(source["sync"], SyncReport);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FileStartup {
+	    file: string;
+	    corruptFile: string;
+	    error: string;
+	    lost: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileStartup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.corruptFile = source["corruptFile"];
+	        this.error = source["error"];
+	        this.lost = source["lost"];
+	    }
+	}
+	export class StartupReport {
+	    catalogs: CatalogStartup[];
+	    files: FileStartup[];
+	
+	    static createFrom(source: any = {}) {
+	        return new StartupReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.catalogs = this.convertValues(source["catalogs"], CatalogStartup);
+	        this.files = this.convertValues(source["files"], FileStartup);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CatalogBackup {
+	    name: string;
+	    // Go type: time
+	    time: any;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CatalogBackup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.size = source["size"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupRestore {
+	    type: string;
+	    backup: CatalogBackup;
+	    sync: SyncReport;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupRestore(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.backup = this.convertValues(source["backup"], CatalogBackup);
+	        this.sync = this.convertValues(source["sync"], SyncReport);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
 }
 
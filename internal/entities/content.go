@@ -0,0 +1,109 @@
+package entities
+
+import (
+	"slices"
+	"time"
+)
+
+type ContentType string
+
+const (
+	ContentTypeModel  ContentType = "model"
+	ContentTypeStage  ContentType = "stage"
+	ContentTypeMotion ContentType = "motion"
+)
+
+// EmbeddingInfo fingerprints an embedding: what produced it and from which text.
+// An embedding is stale when any of these no longer matches.
+type EmbeddingInfo struct {
+	Provider   string `json:"provider"`
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions"`
+	TextHash   string `json:"textHash"`
+}
+
+// Embedding is the generated vectors of an item with their fingerprint. Long
+// descriptions are embedded in several overlapping chunks, one vector each.
+type Embedding struct {
+	Chunks []EmbeddingChunk
+	Info   EmbeddingInfo
+}
+
+// Fields of an item that are embedded separately
+const (
+	// EmbeddingFieldText is the name and description embedded together
+	EmbeddingFieldText        = ""
+	EmbeddingFieldName        = "name"
+	EmbeddingFieldDescription = "description"
+	// EmbeddingFieldPath is the keywords of the original path
+	EmbeddingFieldPath = "path"
+)
+
+// EmbeddingChunk is the vector of a field of an item, or of a passage of its description.
+// Start and End are the byte offsets of the passage in the description, both 0 when the
+// whole field was embedded at once.
+type EmbeddingChunk struct {
+	Field  string
+	Start  int
+	End    int
+	Vector []float64
+}
+
+// Embeddable is the text of a catalog item, used to generate its embedding and
+// indexed for keyword search
+type Embeddable struct {
+	ID          string
+	Name        string
+	Description string
+	Path        string
+}
+
+// EmbeddingResult summarizes an embedding generation run for one content type
+type EmbeddingResult struct {
+	Type      ContentType `json:"type"`
+	Total     int         `json:"total"`
+	Generated int         `json:"generated"`
+	Skipped   int         `json:"skipped"`
+	Stale     int         `json:"stale"`
+	Failed    int         `json:"failed"`
+	Cancelled bool        `json:"cancelled"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// EmbeddingProgress reports how far an embedding generation run is
+type EmbeddingProgress struct {
+	Type           ContentType `json:"type"`
+	Total          int         `json:"total"`
+	Done           int         `json:"done"`
+	Failed         int         `json:"failed"`
+	ElapsedSeconds float64     `json:"elapsedSeconds"`
+	ETASeconds     float64     `json:"etaSeconds"`
+}
+
+// EmbeddingItemEvent reports the state of a single item during a run
+type EmbeddingItemEvent struct {
+	Type  ContentType `json:"type"`
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Error string      `json:"error,omitempty"`
+}
+
+// EmbeddingRun records a generation run so it can be resumed after a crash or cancellation
+type EmbeddingRun struct {
+	StartedAt time.Time     `json:"startedAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+	Types     []ContentType `json:"types"`
+	Completed []ContentType `json:"completed"`
+}
+
+// Remaining returns the content types of the run that did not complete yet
+func (r *EmbeddingRun) Remaining() []ContentType {
+	remaining := make([]ContentType, 0, len(r.Types))
+	for _, t := range r.Types {
+		if !slices.Contains(r.Completed, t) {
+			remaining = append(remaining, t)
+		}
+	}
+
+	return remaining
+}
@@ -1,12 +1,11 @@
 package entities
 
 type Model struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Screenshots  []string  `json:"screenshots"`
-	Description  string    `json:"description"`
-	OriginalPath string    `json:"originalPath"`
-	Embedding    []float64 `json:"embedding,omitempty"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Screenshots  []string `json:"screenshots"`
+	Description  string   `json:"description"`
+	OriginalPath string   `json:"originalPath"`
 }
 
 func (m *Model) Equal(o Model) bool {
@@ -15,16 +14,3 @@ func (m *Model) Equal(o Model) bool {
 		m.OriginalPath == o.OriginalPath &&
 		equalSlices(m.Screenshots, o.Screenshots)
 }
-
-type ModelsData struct {
-	Models []Model `json:"models"`
-}
-
-func (m *ModelsData) Has(o Model) bool {
-	for _, model := range m.Models {
-		if model.Equal(o) {
-			return true
-		}
-	}
-	return false
-}
@@ -1,13 +1,12 @@
 package entities
 
 type Motion struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Screenshots  []string  `json:"screenshots"`
-	Video        []string  `json:"video"`
-	Description  string    `json:"description"`
-	OriginalPath string    `json:"originalPath"`
-	Embedding    []float64 `json:"embedding,omitempty"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Screenshots  []string `json:"screenshots"`
+	Video        []string `json:"video"`
+	Description  string   `json:"description"`
+	OriginalPath string   `json:"originalPath"`
 }
 
 func (m *Motion) Equal(o Motion) bool {
@@ -17,16 +16,3 @@ func (m *Motion) Equal(o Motion) bool {
 		equalSlices(m.Screenshots, o.Screenshots) &&
 		equalSlices(m.Video, o.Video)
 }
-
-type MotionsData struct {
-	Motions []Motion `json:"motions"`
-}
-
-func (m *MotionsData) Has(o Motion) bool {
-	for _, motion := range m.Motions {
-		if motion.Equal(o) {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,47 @@
+package entities
+
+// SearchResult is an item found by a search and how well it matched
+type SearchResult[T any] struct {
+	Item T `json:"item"`
+	// Score is the relevance of the item, from 0 to 1. Semantic searches use the
+	// cosine similarity, keyword and hybrid searches are relative to the best match.
+	Score float64 `json:"score"`
+	// Rank is the position of the item in the results, starting at 1
+	Rank     int       `json:"rank"`
+	Snippets []Snippet `json:"snippets"`
+	// Passage is the part of a long description that matched a semantic search best,
+	// empty when the item matched as a whole
+	Passage string `json:"passage,omitempty"`
+}
+
+// Snippet is a passage of the description with the words matching the query highlighted
+type Snippet struct {
+	Parts []SnippetPart `json:"parts"`
+}
+
+// SnippetPart is a piece of a snippet, Match is set on the highlighted pieces
+type SnippetPart struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match"`
+}
+
+// UnifiedResult is an item of any content type found by a unified search.
+// Only the field of its type is set.
+type UnifiedResult struct {
+	Type     ContentType `json:"type"`
+	Model    *Model      `json:"model,omitempty"`
+	Stage    *Stage      `json:"stage,omitempty"`
+	Motion   *Motion     `json:"motion,omitempty"`
+	Score    float64     `json:"score"`
+	Rank     int         `json:"rank"`
+	Snippets []Snippet   `json:"snippets"`
+	Passage  string      `json:"passage,omitempty"`
+}
+
+// UnifiedSearchResults are the results of a search over every content type
+type UnifiedSearchResults struct {
+	Results []UnifiedResult `json:"results"`
+	// Counts is the number of matches of each content type, before keeping the best overall.
+	// A semantic search without a minimum score counts every item matching the filters.
+	Counts map[ContentType]int `json:"counts"`
+}
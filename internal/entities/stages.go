@@ -1,12 +1,11 @@
 package entities
 
 type Stage struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Screenshots  []string  `json:"screenshots"`
-	Description  string    `json:"description"`
-	OriginalPath string    `json:"originalPath"`
-	Embedding    []float64 `json:"embedding,omitempty"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Screenshots  []string `json:"screenshots"`
+	Description  string   `json:"description"`
+	OriginalPath string   `json:"originalPath"`
 }
 
 func (m *Stage) Equal(o Stage) bool {
@@ -15,16 +14,3 @@ func (m *Stage) Equal(o Stage) bool {
 		m.OriginalPath == o.OriginalPath &&
 		equalSlices(m.Screenshots, o.Screenshots)
 }
-
-type StagesData struct {
-	Stages []Stage `json:"stages"`
-}
-
-func (m *StagesData) Has(o Stage) bool {
-	for _, stage := range m.Stages {
-		if stage.Equal(o) {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,67 @@
+package entities
+
+import "time"
+
+// How a catalog file that could not be loaded was replaced
+const (
+	// RecoveryCreated is a missing catalog file created empty, e.g. on the first launch
+	RecoveryCreated = "created"
+	// RecoveryRestored is a corrupt catalog file replaced with its newest valid backup
+	RecoveryRestored = "restored"
+	// RecoveryRebuilt is a corrupt catalog file without valid backups rebuilt from the folder
+	RecoveryRebuilt = "rebuilt"
+)
+
+// CatalogStartup is what happened to a catalog when the app started
+type CatalogStartup struct {
+	Type ContentType `json:"type"`
+	File string      `json:"file"`
+	// Recovery is empty when the catalog file loaded fine
+	Recovery string `json:"recovery,omitempty"`
+	// Backup is the name of the backup restored
+	Backup string `json:"backup,omitempty"`
+	// CorruptFile is where the corrupt catalog file was moved, to recover it by hand
+	CorruptFile string `json:"corruptFile,omitempty"`
+	// Error is why the catalog file could not be loaded
+	Error string `json:"error,omitempty"`
+	// MissingFolder is the content folder when it was not found, the catalog was then kept
+	// as it was instead of synced, e.g. when the folder is on a drive that is not mounted
+	MissingFolder string     `json:"missingFolder,omitempty"`
+	Sync          SyncReport `json:"sync"`
+}
+
+// FileStartup is a data file other than a catalog that was corrupt when the app started.
+// What could still be read from it was loaded and the app started anyway.
+type FileStartup struct {
+	File string `json:"file"`
+	// CorruptFile is where a copy of the corrupt file was kept, to recover it by hand
+	CorruptFile string `json:"corruptFile"`
+	// Error is why the file could not be loaded entirely
+	Error string `json:"error"`
+	// Lost tells what the corrupt part of the file held, now dropped
+	Lost string `json:"lost"`
+}
+
+// StartupReport tells the user what happened to the catalogs and the other data files
+// when the app started
+type StartupReport struct {
+	Catalogs []CatalogStartup `json:"catalogs"`
+	// Files only lists the data files recovered from corruption
+	Files []FileStartup `json:"files"`
+}
+
+// CatalogBackup is a copy of a catalog file made before it was overwritten
+type CatalogBackup struct {
+	// Name identifies the backup to restore, e.g. models-20261017-090500.123.json
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+}
+
+// BackupRestore is a catalog backup restored, with what changed when the catalog was
+// then synced with its folder
+type BackupRestore struct {
+	Type   ContentType   `json:"type"`
+	Backup CatalogBackup `json:"backup"`
+	Sync   SyncReport    `json:"sync"`
+}
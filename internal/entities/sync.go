@@ -0,0 +1,24 @@
+package entities
+
+// SyncRename is an item whose folder was renamed, found by its unchanged original path
+type SyncRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SyncReport lists the IDs of the items changed when a catalog was synced with its folder
+type SyncReport struct {
+	Type    ContentType  `json:"type"`
+	Added   []string     `json:"added"`
+	Updated []string     `json:"updated"`
+	Removed []string     `json:"removed"`
+	Renamed []SyncRename `json:"renamed"`
+	// Held are items not found in the folder that were kept because most of the catalog was
+	// missing, e.g. an unmounted drive. Refreshing with confirmation removes them.
+	Held []string `json:"held"`
+}
+
+// IsEmpty reports whether the catalog already matched its folder, held items change nothing
+func (r SyncReport) IsEmpty() bool {
+	return len(r.Added) == 0 && len(r.Updated) == 0 && len(r.Removed) == 0 && len(r.Renamed) == 0
+}
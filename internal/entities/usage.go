@@ -0,0 +1,43 @@
+package entities
+
+import "time"
+
+// ModelUsage is the number of tokens billed for a model and their estimated cost in USD
+type ModelUsage struct {
+	Model  string  `json:"model"`
+	Tokens int     `json:"tokens"`
+	Cost   float64 `json:"cost"`
+}
+
+// DailyUsage is the embedding usage of a day, Date is YYYY-MM-DD in local time
+type DailyUsage struct {
+	Date   string       `json:"date"`
+	Tokens int          `json:"tokens"`
+	Cost   float64      `json:"cost"`
+	Models []ModelUsage `json:"models"`
+}
+
+// RunUsage is the embedding usage of a generation run, a resumed run keeps adding to it
+type RunUsage struct {
+	StartedAt time.Time    `json:"startedAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+	Tokens    int          `json:"tokens"`
+	Cost      float64      `json:"cost"`
+	Models    []ModelUsage `json:"models"`
+}
+
+// UsageSummary is the embedding spend shown to the user
+type UsageSummary struct {
+	// Month is the current month, YYYY-MM
+	Month  string  `json:"month"`
+	Tokens int     `json:"tokens"`
+	Cost   float64 `json:"cost"`
+	// Budget is the monthly spending limit in USD, 0 when there is none
+	Budget         float64 `json:"budget"`
+	Remaining      float64 `json:"remaining"`
+	BudgetExceeded bool    `json:"budgetExceeded"`
+	// Days are the days of the month with some usage, oldest first
+	Days []DailyUsage `json:"days"`
+	// Runs are the latest generation runs, most recent first
+	Runs []RunUsage `json:"runs"`
+}
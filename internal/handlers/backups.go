@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+
+	"MMDContent/internal/entities"
+	"MMDContent/internal/storage"
+)
+
+// restorable is a catalog whose file is backed up, see storage.Content
+type restorable interface {
+	Backups() ([]storage.Backup, error)
+	Restore(name string) (storage.Backup, entities.SyncReport, error)
+}
+
+// Backups lists and restores the backups of the catalog files from the frontend,
+// like the restore command does from a console
+type Backups struct {
+	catalogs map[entities.ContentType]restorable
+}
+
+func NewBackups(
+	modelsStorage *storage.Models,
+	stagesStorage *storage.Stages,
+	motionsStorage *storage.Motions,
+) *Backups {
+	return &Backups{
+		catalogs: map[entities.ContentType]restorable{
+			entities.ContentTypeModel:  modelsStorage,
+			entities.ContentTypeStage:  stagesStorage,
+			entities.ContentTypeMotion: motionsStorage,
+		},
+	}
+}
+
+// GetBackups returns the backups of the catalog of a content type, newest first
+func (a *Backups) GetBackups(contentType entities.ContentType) ([]entities.CatalogBackup, error) {
+	catalog, err := a.catalog(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	backups, err := catalog.Backups()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]entities.CatalogBackup, len(backups))
+	for i, backup := range backups {
+		list[i] = catalogBackup(backup)
+	}
+
+	return list, nil
+}
+
+// RestoreBackup replaces the catalog of a content type with one of its backups, the newest
+// one when name is empty, and syncs it with its folder. The current catalog is backed up
+// first, so a restore can be undone.
+func (a *Backups) RestoreBackup(contentType entities.ContentType, name string) (entities.BackupRestore, error) {
+	catalog, err := a.catalog(contentType)
+	if err != nil {
+		return entities.BackupRestore{}, err
+	}
+
+	backup, report, err := catalog.Restore(name)
+	if err != nil {
+		return entities.BackupRestore{}, err
+	}
+
+	return entities.BackupRestore{
+		Type:   contentType,
+		Backup: catalogBackup(backup),
+		Sync:   report,
+	}, nil
+}
+
+func (a *Backups) catalog(contentType entities.ContentType) (restorable, error) {
+	catalog, ok := a.catalogs[contentType]
+	if !ok {
+		return nil, fmt.Errorf("unknown content type %q", contentType)
+	}
+
+	return catalog, nil
+}
+
+func catalogBackup(backup storage.Backup) entities.CatalogBackup {
+	return entities.CatalogBackup{
+		Name: backup.Name,
+		Time: backup.Time,
+		Size: backup.Size,
+	}
+}
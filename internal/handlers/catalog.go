@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"sort"
+
+	"MMDContent/internal/entities"
+	"MMDContent/internal/search"
+	"MMDContent/internal/services/embedding"
+)
+
+// Catalog searches models, stages and motions at once
+type Catalog struct {
+	provider embedding.Provider
+	models   *Models
+	stages   *Stages
+	motions  *Motions
+	latest   latestSearch
+}
+
+func NewCatalog(
+	provider embedding.Provider,
+	models *Models,
+	stages *Stages,
+	motions *Motions,
+) *Catalog {
+	return &Catalog{
+		provider: provider,
+		models:   models,
+		stages:   stages,
+		motions:  motions,
+	}
+}
+
+// SearchAll runs SearchModels, SearchStages and SearchMotions with a single query
+// embedding and returns the best limit results of any type, best first, with the number
+// of matches of each type. Semantic scores of all types are comparable since they share
+// the embedding model. A match scores at least minScore: without it every item has some
+// similarity, and the semantic counts are the totals of items matching the filters.
+func (c *Catalog) SearchAll(query string, limit int, minScore float64) (entities.UnifiedSearchResults, error) {
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		return entities.UnifiedSearchResults{}, err
+	}
+
+	var queryEmbedding []float64
+	if c.provider != nil && q.Text() != "" {
+		queryEmbedding, err = embedQuery(&c.latest, c.provider, q.Text())
+		if err != nil {
+			return entities.UnifiedSearchResults{}, err
+		}
+	}
+
+	// The best limit of each type are enough to find the best limit overall
+	models, modelCount := c.models.content.matches(q, queryEmbedding, limit, minScore)
+	stages, stageCount := c.stages.content.matches(q, queryEmbedding, limit, minScore)
+	motions, motionCount := c.motions.content.matches(q, queryEmbedding, limit, minScore)
+
+	type ranked struct {
+		contentType entities.ContentType
+		score       float64
+		index       int
+	}
+	best := make([]ranked, 0, len(models)+len(stages)+len(motions))
+	for i, m := range models {
+		best = append(best, ranked{entities.ContentTypeModel, m.hit.Score, i})
+	}
+	for i, m := range stages {
+		best = append(best, ranked{entities.ContentTypeStage, m.hit.Score, i})
+	}
+	for i, m := range motions {
+		best = append(best, ranked{entities.ContentTypeMotion, m.hit.Score, i})
+	}
+
+	// Ties keep the order above: models, stages, then motions
+	sort.SliceStable(best, func(i, j int) bool {
+		return best[i].score > best[j].score
+	})
+
+	if limit > 0 && len(best) > limit {
+		best = best[:limit]
+	}
+
+	// Snippets are only made for the results kept
+	results := make([]entities.UnifiedResult, len(best))
+	for i, r := range best {
+		var fields search.Fields
+		var hit search.Hit
+		switch r.contentType {
+		case entities.ContentTypeModel:
+			results[i].Model = &models[r.index].item
+			fields, hit = models[r.index].fields, models[r.index].hit
+		case entities.ContentTypeStage:
+			results[i].Stage = &stages[r.index].item
+			fields, hit = stages[r.index].fields, stages[r.index].hit
+		case entities.ContentTypeMotion:
+			results[i].Motion = &motions[r.index].item
+			fields, hit = motions[r.index].fields, motions[r.index].hit
+		}
+
+		results[i].Type = r.contentType
+		results[i].Score = hit.Score
+		results[i].Rank = i + 1
+		results[i].Snippets = search.Snippets(fields.Description, q.Text())
+		results[i].Passage = passage(fields.Description, hit)
+	}
+
+	return entities.UnifiedSearchResults{
+		Results: results,
+		Counts: map[entities.ContentType]int{
+			entities.ContentTypeModel:  modelCount,
+			entities.ContentTypeStage:  stageCount,
+			entities.ContentTypeMotion: motionCount,
+		},
+	}, nil
+}
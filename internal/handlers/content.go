@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"fmt"
+
+	"MMDContent/internal/entities"
+	"MMDContent/internal/search"
+	"MMDContent/internal/services/embedding"
+	"MMDContent/internal/storage"
+)
+
+// contentSearch searches, lists and refreshes the catalog of a content type. The bound
+// handlers of models, stages and motions only expose its methods under their own names.
+type contentSearch[T any] struct {
+	provider embedding.Provider
+	index    *search.VectorIndex
+	keywords *search.KeywordIndex
+	weights  search.HybridWeights
+	storage  *storage.Content[T]
+	// fields returns the attributes of an item used by query filters
+	fields func(item T) search.Fields
+	latest latestSearch
+}
+
+func newContentSearch[T any](
+	provider embedding.Provider,
+	index *search.VectorIndex,
+	keywords *search.KeywordIndex,
+	weights search.HybridWeights,
+	contentStorage *storage.Content[T],
+	fields func(item T) search.Fields,
+) *contentSearch[T] {
+	return &contentSearch[T]{
+		provider: provider,
+		index:    index,
+		keywords: keywords,
+		weights:  weights,
+		storage:  contentStorage,
+		fields:   fields,
+	}
+}
+
+// semanticSearch searches using semantic similarity with embeddings, or by keywords
+// when no embedding provider is configured
+func (c *contentSearch[T]) semanticSearch(query string, limit int, minScore float64) ([]entities.SearchResult[T], error) {
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.provider == nil || q.Text() == "" {
+		return c.keywordSearch(q, limit, minScore), nil
+	}
+
+	if c.storage.IsEmpty() {
+		return []entities.SearchResult[T]{}, nil
+	}
+
+	queryEmbedding, err := embedQuery(&c.latest, c.provider, q.Text())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.search(q, queryEmbedding, limit, minScore), nil
+}
+
+// search runs semanticSearch with the embedding of the query text already generated,
+// nil to search by keywords
+func (c *contentSearch[T]) search(q search.Query, queryEmbedding []float64, limit int, minScore float64) []entities.SearchResult[T] {
+	if queryEmbedding == nil {
+		return c.keywordSearch(q, limit, minScore)
+	}
+
+	// Look up the closest items in the vector index
+	hits := c.index.Search(c.storage.ContentType(), queryEmbedding, candidates(q, limit))
+
+	return c.results(hits, q, limit, minScore)
+}
+
+// matches runs search without making the results, along with the number of items matching
+// the query. That count scores every item, unless the search is semantic without minScore:
+// every item then has some similarity and the count is of the items matching the filters.
+func (c *contentSearch[T]) matches(q search.Query, queryEmbedding []float64, limit int, minScore float64) ([]searchMatch[T], int) {
+	if c.storage.IsEmpty() || q.IsEmpty() {
+		return nil, 0
+	}
+
+	if queryEmbedding != nil && minScore <= 0 {
+		hits := c.index.Search(c.storage.ContentType(), queryEmbedding, candidates(q, limit))
+		return matchHits(hits, q, limit, minScore, c.storage.Find, c.fields), c.count(q)
+	}
+
+	var hits []search.Hit
+	switch {
+	case queryEmbedding != nil:
+		hits = c.index.Search(c.storage.ContentType(), queryEmbedding, 0)
+	case q.Text() == "":
+		hits = filteredHits(c.storage.Items(), q, c.fields)
+	default:
+		hits = c.keywords.Search(c.storage.ContentType(), q.Text(), 0)
+	}
+
+	matches := matchHits(hits, q, 0, minScore, c.storage.Find, c.fields)
+	count := len(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, count
+}
+
+// count returns the number of items matching the filters of the query
+func (c *contentSearch[T]) count(q search.Query) int {
+	if q.Filter == nil {
+		return c.storage.Total()
+	}
+
+	count := 0
+	for _, item := range c.storage.Items() {
+		if q.Match(c.fields(item)) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// hybridSearch combines keyword and semantic rankings, so that an item whose name
+// is typed verbatim ranks first while similar descriptions still match
+func (c *contentSearch[T]) hybridSearch(query string, limit int, minScore float64) ([]entities.SearchResult[T], error) {
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.provider == nil || q.Text() == "" {
+		return c.keywordSearch(q, limit, minScore), nil
+	}
+
+	if c.storage.IsEmpty() {
+		return []entities.SearchResult[T]{}, nil
+	}
+
+	queryEmbedding, err := embedQuery(&c.latest, c.provider, q.Text())
+	if err != nil {
+		return nil, err
+	}
+
+	n := candidates(q, search.FusionCandidates(limit))
+	semantic := c.index.Search(c.storage.ContentType(), queryEmbedding, n)
+	keyword := c.keywords.Search(c.storage.ContentType(), q.Text(), n)
+	hits := search.Hybrid(keyword, semantic, c.weights, n)
+
+	return c.results(hits, q, limit, minScore), nil
+}
+
+// similarTo returns the items most similar to an item of any content type, using its
+// stored embedding so that no API call is needed
+func (c *contentSearch[T]) similarTo(contentType entities.ContentType, id string, limit int) ([]entities.SearchResult[T], error) {
+	if c.storage.IsEmpty() {
+		return []entities.SearchResult[T]{}, nil
+	}
+
+	hits, ok := c.index.Similar(contentType, id, c.storage.ContentType(), limit)
+	if !ok {
+		return nil, fmt.Errorf("%s %q: %w", contentType, id, ErrNoEmbedding)
+	}
+
+	return c.results(hits, search.Query{}, limit, 0), nil
+}
+
+// keywordQuery searches by name, description and path with BM25 ranking, without
+// calling any embedding provider
+func (c *contentSearch[T]) keywordQuery(query string, limit int, minScore float64) ([]entities.SearchResult[T], error) {
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.keywordSearch(q, limit, minScore), nil
+}
+
+func (c *contentSearch[T]) keywordSearch(q search.Query, limit int, minScore float64) []entities.SearchResult[T] {
+	if c.storage.IsEmpty() || q.IsEmpty() {
+		return []entities.SearchResult[T]{}
+	}
+
+	// Only filters, every matching item is a result
+	if q.Text() == "" {
+		return c.results(filteredHits(c.storage.Items(), q, c.fields), q, limit, minScore)
+	}
+
+	hits := c.keywords.Search(c.storage.ContentType(), q.Text(), candidates(q, limit))
+
+	return c.results(hits, q, limit, minScore)
+}
+
+// results returns the items of the hits matching the query filters and scoring at least minScore
+func (c *contentSearch[T]) results(hits []search.Hit, q search.Query, limit int, minScore float64) []entities.SearchResult[T] {
+	return searchResults(hits, q, limit, minScore, c.storage.Find, c.fields)
+}
+
+// paginated returns a page of the items matching the query, of all of them when it is empty
+func (c *contentSearch[T]) paginated(page, perPage int, query string) (entities.Pagination[T], error) {
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		return entities.Pagination[T]{}, err
+	}
+
+	if c.storage.IsEmpty() {
+		return entities.Pagination[T]{
+			Data:       []T{},
+			Total:      0,
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: 0,
+		}, nil
+	}
+
+	return c.storage.GetPaginated(page, perPage, c.matcher(q)), nil
+}
+
+// all returns every item without pagination
+func (c *contentSearch[T]) all() []T {
+	if c.storage.IsEmpty() {
+		return []T{}
+	}
+
+	return c.storage.Items()
+}
+
+// export returns every item matching the query, in catalog order
+func (c *contentSearch[T]) export(query string) ([]T, error) {
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.storage.IsEmpty() {
+		return []T{}, nil
+	}
+
+	match := c.matcher(q)
+	items := make([]T, 0)
+	for _, item := range c.storage.Items() {
+		if match == nil || match(item) {
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// refresh syncs the catalog with its folder, most of the catalog missing from the
+// folder is only removed with confirmRemovals
+func (c *contentSearch[T]) refresh(confirmRemovals bool) (entities.SyncReport, error) {
+	if confirmRemovals {
+		return c.storage.RefreshConfirmed()
+	}
+
+	return c.storage.Refresh()
+}
+
+// matcher returns the filter used by pagination and export, nil to keep every item.
+// Without ranking, items must contain the free text of the query as well.
+func (c *contentSearch[T]) matcher(q search.Query) func(T) bool {
+	if q.IsEmpty() {
+		return nil
+	}
+
+	return func(item T) bool {
+		return q.MatchAll(c.fields(item))
+	}
+}
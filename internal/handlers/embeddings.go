@@ -1,178 +1,554 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"MMDContent/internal/entities"
-	"MMDContent/internal/services/openai"
+	"MMDContent/internal/services/embedding"
 	"MMDContent/internal/storage"
 )
 
+// EmbeddingStorage is a content storage whose items can be embedded.
+// Every content type (models, stages, motions...) registers one.
+type EmbeddingStorage interface {
+	ContentType() entities.ContentType
+	Refresh() (entities.SyncReport, error)
+	Embeddables() []entities.Embeddable
+}
+
+// Events emitted while generating embeddings
+const (
+	EventEmbeddingsStarted    = "embeddings:started"
+	EventEmbeddingItemStarted = "embeddings:item-started"
+	EventEmbeddingItemDone    = "embeddings:item-done"
+	EventEmbeddingItemFailed  = "embeddings:item-failed"
+	EventEmbeddingsProgress   = "embeddings:progress"
+	EventEmbeddingsFinished   = "embeddings:finished"
+)
+
+// Generated embeddings are persisted every checkpointItems items or checkpointInterval,
+// whichever comes first, so a crash or a quit loses at most one checkpoint of work
+const (
+	checkpointItems    = 50
+	checkpointInterval = 10 * time.Second
+)
+
+// maxRejectedSplits bounds the requests spent isolating the texts a backend rejects in a
+// batch, a request refused whatever its texts (e.g. a bad parameter) then fails quickly
+const maxRejectedSplits = 32
+
+var (
+	ErrEmbeddingsRunning = errors.New("embedding generation is already running")
+	ErrNoUnfinishedRun   = errors.New("there is no unfinished embedding run to resume")
+	ErrNoProvider        = errors.New("no embedding provider is configured")
+)
+
+// EmbeddingFields selects how the fields of an item are embedded
+type EmbeddingFields string
+
+const (
+	// FieldsSeparate embeds the name, the description and the path keywords apart,
+	// so that search can weight them, at up to three inputs per item
+	FieldsSeparate EmbeddingFields = "separate"
+	// FieldsCombined embeds the name and description together, the default, which keeps
+	// the embeddings made before fields existed fresh
+	FieldsCombined EmbeddingFields = "combined"
+)
+
+// EventEmitter sends an event and its payload to the frontend
+type EventEmitter func(event string, data any)
+
 type Embeddings struct {
-	client        openai.Client
-	modelsStorage *storage.Models
-	stagesStorage *storage.Stages
+	provider embedding.Provider
+	emit     EventEmitter
+	runs     *storage.EmbeddingRuns
+	vectors  *storage.Vectors
+	usage    *UsageMeter
+	storages []EmbeddingStorage
+
+	separateFields bool
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
 }
 
 func NewEmbeddings(
-	client openai.Client,
+	provider embedding.Provider,
+	emit EventEmitter,
+	runs *storage.EmbeddingRuns,
+	vectors *storage.Vectors,
+	usage *UsageMeter,
+	fields EmbeddingFields,
+	storages ...EmbeddingStorage,
 ) *Embeddings {
+	if emit == nil {
+		emit = func(string, any) {}
+	}
+
 	return &Embeddings{
-		client: client,
+		provider: provider,
+		emit:     emit,
+		runs:     runs,
+		vectors:  vectors,
+		usage:    usage,
+		storages: storages,
+
+		separateFields: fields == FieldsSeparate,
 	}
 }
 
-func (e *Embeddings) GenerateAll() {
-	// Generate embeddings for models
-	if err := e.GenerateModelsEmbeddings(); err != nil {
-		slog.Error("error generating model embeddings", "error", err)
-		return
-	}
+// GenerateAll generates the missing embeddings of every registered content type.
+// A failing type does not stop the others, its error is reported in its result.
+func (e *Embeddings) GenerateAll() ([]entities.EmbeddingResult, error) {
+	return e.run(e.storages, nil)
+}
 
-	// Generate embeddings for stages
-	if err := e.GenerateStagesEmbeddings(); err != nil {
-		slog.Error("error generating stage embeddings", "error", err)
-		return
+// GenerateEmbeddings generates the missing embeddings of a single content type
+func (e *Embeddings) GenerateEmbeddings(contentType entities.ContentType) (entities.EmbeddingResult, error) {
+	for _, s := range e.storages {
+		if s.ContentType() == contentType {
+			results, err := e.run([]EmbeddingStorage{s}, nil)
+			if err != nil {
+				return entities.EmbeddingResult{}, err
+			}
+			return results[0], nil
+		}
 	}
+
+	return entities.EmbeddingResult{}, fmt.Errorf("unknown content type %q", contentType)
+}
+
+// GetUnfinishedRun returns the run that was cancelled or interrupted, or nil if there is none
+func (e *Embeddings) GetUnfinishedRun() (*entities.EmbeddingRun, error) {
+	return e.runs.Load()
 }
 
-// GenerateModelsEmbeddings generates embeddings for all models
-func (e *Embeddings) GenerateModelsEmbeddings() error {
-	err := e.modelsStorage.Refresh()
+// Resume continues the unfinished run with the content types it did not complete.
+// Items saved by a checkpoint already have their embedding and are skipped.
+func (e *Embeddings) Resume() ([]entities.EmbeddingResult, error) {
+	state, err := e.runs.Load()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if state == nil {
+		return nil, ErrNoUnfinishedRun
 	}
 
-	totalModels := e.modelsStorage.Total()
-	skippedCount := 0
-	updatedCount := 0
-	failedCount := 0
-
-	fmt.Printf("   Found %d models total\n", totalModels)
+	var storages []EmbeddingStorage
+	for _, contentType := range state.Remaining() {
+		for _, s := range e.storages {
+			if s.ContentType() == contentType {
+				storages = append(storages, s)
+			}
+		}
+	}
 
-	updatedModels := make([]entities.Model, totalModels)
-	for i, model := range e.modelsStorage.Get().Models {
-		// Skip if embedding already exists
-		if len(model.Embedding) > 0 {
-			skippedCount++
-			continue
+	if len(storages) == 0 {
+		// None of the remaining types is registered anymore, nothing left to resume
+		if err := e.runs.Clear(); err != nil {
+			return nil, err
 		}
+		return nil, ErrNoUnfinishedRun
+	}
 
-		// Prepare text for embedding
-		text := PrepareTextForEmbedding(model.Name, model.Description)
+	return e.run(storages, state)
+}
 
-		fmt.Printf("   [%d/%d] Generating embedding for: %s\n", i+1, totalModels, model.Name)
+// Cancel stops the running generation. Embeddings generated so far are saved.
+func (e *Embeddings) Cancel() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-		// Generate embedding
-		embedding, err := e.client.GenerateEmbedding(text)
-		if err != nil {
-			fmt.Printf("   ⚠️  Warning: Failed for %s: %v\n", model.ID, err)
-			failedCount++
-			continue
-		}
+	if e.cancel != nil {
+		e.cancel()
+	}
+}
+
+// IsRunning reports whether an embedding generation is in progress
+func (e *Embeddings) IsRunning() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.cancel != nil
+}
+
+// embeddingJob holds the items of one content type waiting for an embedding.
+// Every item has one text per part (field or chunk of its description), parts
+// holding them without vectors; texts, owners and chunkOf run in parallel,
+// owners giving the index of the item of each text.
+type embeddingJob struct {
+	storage EmbeddingStorage
+	items   []entities.Embeddable
+	parts   [][]entities.EmbeddingChunk
+	hashes  []string
+	texts   []string
+	owners  []int
+	chunkOf []int
+	result  entities.EmbeddingResult
+}
 
-		model.Embedding = embedding
-		updatedCount++
+// run generates the embeddings of the given storages. state is the unfinished run
+// being resumed, or nil to start a new one.
+func (e *Embeddings) run(storages []EmbeddingStorage, state *entities.EmbeddingRun) ([]entities.EmbeddingResult, error) {
+	if e.provider == nil {
+		return nil, ErrNoProvider
+	}
 
-		// Small delay to avoid rate limits (optional, adjust based on your API tier)
-		time.Sleep(100 * time.Millisecond)
+	if err := e.usage.allow(e.provider.Model(), 0); err != nil {
+		return nil, err
+	}
 
-		updatedModels[i] = model
+	e.mu.Lock()
+	if e.cancel != nil {
+		e.mu.Unlock()
+		return nil, ErrEmbeddingsRunning
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		e.cancel = nil
+		e.mu.Unlock()
+		cancel()
+	}()
+
+	if state == nil {
+		state = &entities.EmbeddingRun{StartedAt: time.Now()}
+		for _, s := range storages {
+			state.Types = append(state.Types, s.ContentType())
+		}
+	}
+	e.saveRunState(state)
+
+	// Requests of the run are counted in its usage, a resumed run keeps adding to it
+	ctx = withUsageRun(ctx, state.StartedAt)
+
+	// Plan every job first so that totals and ETA cover the whole run
+	progress := newEmbeddingProgress()
+	jobs := make([]*embeddingJob, 0, len(storages))
+	for _, s := range storages {
+		job := e.plan(s)
+		progress.total += len(job.items)
+		jobs = append(jobs, job)
 	}
 
-	fmt.Printf("\n   ✅ Generated: %d | ⏭️  Skipped: %d | ❌ Failed: %d\n", updatedCount, skippedCount, failedCount)
+	e.emit(EventEmbeddingsStarted, progress.snapshot(""))
+
+	results := make([]entities.EmbeddingResult, 0, len(jobs))
+	for _, job := range jobs {
+		if job.result.Error == "" {
+			e.execute(ctx, job, progress)
+		}
+		if job.result.Error != "" {
+			slog.Error("error generating embeddings", "type", job.result.Type, "error", job.result.Error)
+		}
+		if job.result.Error == "" && !job.result.Cancelled {
+			state.Completed = append(state.Completed, job.result.Type)
+			e.saveRunState(state)
+		}
+		results = append(results, job.result)
+	}
 
-	// Save updated data back to file
-	if updatedCount > 0 {
-		fmt.Println("   💾 Saving models data...")
-		e.modelsStorage.Set(&entities.ModelsData{Models: updatedModels})
-		if err := e.modelsStorage.Save(); err != nil {
-			return err
+	if len(state.Remaining()) == 0 {
+		if err := e.runs.Clear(); err != nil {
+			slog.Error("error clearing embedding run state", "error", err)
 		}
-		fmt.Println("   ✅ Models data saved successfully")
-	} else {
-		fmt.Println("   ℹ️  No new embeddings to save")
 	}
 
-	return nil
+	e.emit(EventEmbeddingsFinished, results)
+
+	return results, nil
 }
 
-// GenerateStagesEmbeddings generates embeddings for all stages
-func (e *Embeddings) GenerateStagesEmbeddings() error {
-	err := e.modelsStorage.Refresh()
+// plan refreshes the storage and collects the items that still need an embedding
+func (e *Embeddings) plan(s EmbeddingStorage) *embeddingJob {
+	job := &embeddingJob{
+		storage: s,
+		result:  entities.EmbeddingResult{Type: s.ContentType()},
+	}
+
+	_, err := s.Refresh()
 	if err != nil {
-		return err
+		job.result.Error = err.Error()
+		return job
 	}
 
-	totalStages := e.stagesStorage.Total()
-	skippedCount := 0
-	updatedCount := 0
-	failedCount := 0
+	items := s.Embeddables()
+	job.result.Total = len(items)
 
-	fmt.Printf("   Found %d stages total\n", totalStages)
+	for _, item := range items {
+		parts, texts, hash := embeddingParts(item, e.separateFields)
+		stored, embedded := e.vectors.Get(job.result.Type, item.ID)
 
-	updatedStages := make([]entities.Stage, totalStages)
-	for i, stage := range e.stagesStorage.Get().Stages {
-		// Skip if embedding already exists
-		if len(stage.Embedding) > 0 {
-			skippedCount++
+		// Skip if the embedding is up to date. Embeddings made before fingerprints existed
+		// never are, their text is unknown, search keeps using them until they are replaced.
+		if embedded && e.isFresh(stored.Info, hash) && len(stored.Chunks) == len(parts) {
+			job.result.Skipped++
 			continue
 		}
 
-		// Prepare text for embedding
-		text := PrepareTextForEmbedding(stage.Name, stage.Description)
+		if embedded {
+			job.result.Stale++
+		}
 
-		fmt.Printf("   [%d/%d] Generating embedding for: %s\n", i+1, totalStages, stage.Name)
+		owner := len(job.items)
+		job.items = append(job.items, item)
+		job.parts = append(job.parts, parts)
+		job.hashes = append(job.hashes, hash)
+		for n, text := range texts {
+			job.texts = append(job.texts, text)
+			job.owners = append(job.owners, owner)
+			job.chunkOf = append(job.chunkOf, n)
+		}
+	}
 
-		// Generate embedding
-		embedding, err := e.client.GenerateEmbedding(text)
-		if err != nil {
-			fmt.Printf("   ⚠️  Warning: Failed for %s: %v\n", stage.ID, err)
-			failedCount++
-			continue
+	return job
+}
+
+// fingerprint describes an embedding generated by the current provider
+func (e *Embeddings) fingerprint(hash string, dimensions int) entities.EmbeddingInfo {
+	return entities.EmbeddingInfo{
+		Provider:   e.provider.Name(),
+		Model:      e.provider.Model(),
+		Dimensions: dimensions,
+		TextHash:   hash,
+	}
+}
+
+// isFresh reports whether an embedding with the given fingerprint matches the current
+// provider and text, i.e. whether it would be generated again identically
+func (e *Embeddings) isFresh(info entities.EmbeddingInfo, hash string) bool {
+	if dimensions := e.provider.Dimensions(); dimensions > 0 && info.Dimensions != dimensions {
+		return false
+	}
+
+	return info.Provider == e.provider.Name() &&
+		info.Model == e.provider.Model() &&
+		info.TextHash == hash
+}
+
+// textHash identifies the exact text an embedding was generated from
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// execute embeds the job items in token-aware batches until done or cancelled,
+// then saves whatever was generated. An item is saved once all of its chunks are
+// embedded, the chunks of an item may span several batches.
+func (e *Embeddings) execute(ctx context.Context, job *embeddingJob, progress *embeddingProgress) {
+	contentType := job.result.Type
+	embeddings := make(map[string]entities.Embedding, checkpointItems)
+	partial := make(map[int]*entities.Embedding)
+	failed := make(map[int]bool)
+	lastCheckpoint := time.Now()
+
+	for _, batch := range embedding.SplitBatches(job.texts, embedding.DefaultBatchTokens, embedding.DefaultBatchInputs) {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Stop before the batch that would go over the budget, the run can be resumed later
+		tokens := 0
+		for _, text := range job.texts[batch.Start:batch.End] {
+			tokens += embedding.EstimateTokens(text)
+		}
+		if err := e.usage.allow(e.provider.Model(), tokens); err != nil {
+			job.result.Error = err.Error()
+			break
+		}
+
+		owners := job.owners[batch.Start:batch.End]
+		for i, owner := range owners {
+			if job.chunkOf[batch.Start+i] == 0 {
+				item := job.items[owner]
+				e.emit(EventEmbeddingItemStarted, entities.EmbeddingItemEvent{Type: contentType, ID: item.ID, Name: item.Name})
+			}
 		}
 
-		stage.Embedding = embedding
-		updatedCount++
+		generated, errs := e.generate(ctx, job.texts[batch.Start:batch.End])
+		failures := 0
+		var failure error
+		for _, err := range errs {
+			if err != nil {
+				failures++
+				failure = err
+			}
+		}
+		if failures > 0 && ctx.Err() != nil {
+			// Cancelled in the middle of the batch, its items are left for the next run
+			break
+		}
+		if failures > 0 {
+			slog.Warn("failed to generate embeddings", "type", contentType, "inputs", len(owners), "failed", failures, "error", failure)
+		}
+
+		for i, owner := range owners {
+			if failed[owner] {
+				continue
+			}
+
+			if err := errs[i]; err != nil {
+				failed[owner] = true
+				delete(partial, owner)
+
+				item := job.items[owner]
+				e.emit(EventEmbeddingItemFailed, entities.EmbeddingItemEvent{Type: contentType, ID: item.ID, Name: item.Name, Error: err.Error()})
+				job.result.Failed++
+				progress.failed++
+				continue
+			}
+
+			emb, ok := partial[owner]
+			if !ok {
+				emb = &entities.Embedding{
+					Chunks: make([]entities.EmbeddingChunk, len(job.parts[owner])),
+					Info:   e.fingerprint(job.hashes[owner], len(generated[i])),
+				}
+				partial[owner] = emb
+			}
+
+			n := job.chunkOf[batch.Start+i]
+			emb.Chunks[n] = job.parts[owner][n]
+			emb.Chunks[n].Vector = generated[i]
+
+			if n < len(emb.Chunks)-1 {
+				continue
+			}
+
+			item := job.items[owner]
+			embeddings[item.ID] = *emb
+			delete(partial, owner)
+			e.emit(EventEmbeddingItemDone, entities.EmbeddingItemEvent{Type: contentType, ID: item.ID, Name: item.Name})
+			job.result.Generated++
+			progress.done++
+		}
+
+		e.emit(EventEmbeddingsProgress, progress.snapshot(contentType))
+
+		if len(embeddings) >= checkpointItems || (len(embeddings) > 0 && time.Since(lastCheckpoint) >= checkpointInterval) {
+			if err := e.checkpoint(job, embeddings); err != nil {
+				job.result.Error = err.Error()
+				return
+			}
+			clear(embeddings)
+			lastCheckpoint = time.Now()
+		}
+	}
 
-		// Small delay to avoid rate limits
-		time.Sleep(100 * time.Millisecond)
+	job.result.Cancelled = ctx.Err() != nil
 
-		updatedStages[i] = stage
+	if err := e.checkpoint(job, embeddings); err != nil {
+		job.result.Error = err.Error()
 	}
+}
+
+// generate embeds a batch of texts. When the backend rejects the batch because of its texts,
+// its halves are sent again until the texts at fault are found, so that they alone fail.
+// errs holds the error of every text left without an embedding.
+func (e *Embeddings) generate(ctx context.Context, texts []string) (generated [][]float64, errs []error) {
+	generated = make([][]float64, len(texts))
+	errs = make([]error, len(texts))
+	splits := 0
+
+	var embed func(start, end int)
+	embed = func(start, end int) {
+		values, err := e.provider.GenerateEmbeddings(ctx, texts[start:end])
+		if err == nil {
+			copy(generated[start:end], values)
+			return
+		}
 
-	fmt.Printf("\n   ✅ Generated: %d | ⏭️  Skipped: %d | ❌ Failed: %d\n", updatedCount, skippedCount, failedCount)
+		if end-start > 1 && splits < maxRejectedSplits && ctx.Err() == nil && embedding.IsInputRejected(err) {
+			slog.Warn("batch of embeddings rejected, splitting it", "inputs", end-start, "error", err)
+			splits++
+			middle := (start + end) / 2
+			embed(start, middle)
+			embed(middle, end)
+			return
+		}
 
-	// Save updated data back to file
-	if updatedCount > 0 {
-		fmt.Println("   💾 Saving stages data...")
-		e.stagesStorage.Set(&entities.StagesData{Stages: updatedStages})
-		if err := e.stagesStorage.Save(); err != nil {
-			return err
+		for i := start; i < end; i++ {
+			errs[i] = err
 		}
-		fmt.Println("   ✅ Stages data saved successfully")
-	} else {
-		fmt.Println("   ℹ️  No new embeddings to save")
 	}
+	embed(0, len(texts))
+
+	return generated, errs
+}
+
+// checkpoint persists the embeddings generated since the previous checkpoint
+func (e *Embeddings) checkpoint(job *embeddingJob, embeddings map[string]entities.Embedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	return e.vectors.Put(job.result.Type, embeddings)
+}
+
+func (e *Embeddings) saveRunState(state *entities.EmbeddingRun) {
+	state.UpdatedAt = time.Now()
+	if err := e.runs.Save(state); err != nil {
+		slog.Error("error saving embedding run state", "error", err)
+	}
+}
+
+// embeddingProgress tracks a run across all content types to estimate the remaining time
+type embeddingProgress struct {
+	started time.Time
+	total   int
+	done    int
+	failed  int
+}
 
-	return nil
+func newEmbeddingProgress() *embeddingProgress {
+	return &embeddingProgress{started: time.Now()}
+}
+
+func (p *embeddingProgress) snapshot(contentType entities.ContentType) entities.EmbeddingProgress {
+	elapsed := time.Since(p.started).Seconds()
+
+	eta := 0.0
+	processed := p.done + p.failed
+	if processed > 0 {
+		eta = elapsed / float64(processed) * float64(p.total-processed)
+	}
+
+	return entities.EmbeddingProgress{
+		Type:           contentType,
+		Total:          p.total,
+		Done:           p.done,
+		Failed:         p.failed,
+		ElapsedSeconds: elapsed,
+		ETASeconds:     eta,
+	}
 }
 
 // CosineSimilarity calculates the cosine similarity between two vectors
 // Returns a value between -1 and 1, where 1 means identical, 0 means orthogonal, -1 means opposite
-func CosineSimilarity(a, b []float64) float64 {
+func CosineSimilarity[A, B float32 | float64](a []A, b []B) float64 {
 	if len(a) != len(b) {
 		return 0
 	}
 
 	var dotProduct, normA, normB float64
 	for i := range a {
-		dotProduct += a[i] * b[i]
-		normA += a[i] * a[i]
-		normB += b[i] * b[i]
+		x, y := float64(a[i]), float64(b[i])
+		dotProduct += x * y
+		normA += x * x
+		normB += y * y
 	}
 
 	if normA == 0 || normB == 0 {
@@ -186,3 +562,83 @@ func CosineSimilarity(a, b []float64) float64 {
 func PrepareTextForEmbedding(name, description string) string {
 	return fmt.Sprintf("Name: %s\nDescription: %s", name, description)
 }
+
+// maxPathSegments keeps the folders closest to an item, upper ones are rarely specific to it
+const maxPathSegments = 4
+
+// PathKeywords returns the words of the folders holding an item, e.g.
+// "D:\MMD\Stages\Sakura_Temple-v2\stage.pmx" gives "MMD Stages Sakura Temple v2".
+// The last element is the name of the item and is left out.
+func PathKeywords(path string) string {
+	segments := strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' })
+	if len(segments) > 0 {
+		segments = segments[:len(segments)-1]
+	}
+
+	// Drive letters are not keywords
+	if len(segments) > 0 && strings.HasSuffix(segments[0], ":") {
+		segments = segments[1:]
+	}
+	if len(segments) > maxPathSegments {
+		segments = segments[len(segments)-maxPathSegments:]
+	}
+
+	words := strings.FieldsFunc(strings.Join(segments, " "), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	return strings.Join(words, " ")
+}
+
+// embeddingParts returns the parts of an item to embed without their vectors, the text
+// of each and a hash identifying them all. Combined parts hold the name and description
+// together, separate parts embed the name, the description and the path keywords apart.
+// Long descriptions are split into chunks either way.
+func embeddingParts(item entities.Embeddable, separateFields bool) ([]entities.EmbeddingChunk, []string, string) {
+	chunks := embedding.SplitChunks(item.Description, embedding.DefaultChunkTokens, embedding.DefaultChunkOverlap)
+	text := PrepareTextForEmbedding(item.Name, item.Description)
+
+	var parts []entities.EmbeddingChunk
+	var texts []string
+	if !separateFields {
+		if len(chunks) == 1 {
+			return []entities.EmbeddingChunk{{}}, []string{text}, textHash(text)
+		}
+
+		// The name is repeated in each chunk so that every passage keeps its context
+		for _, chunk := range chunks {
+			parts = append(parts, entities.EmbeddingChunk{Start: chunk.Start, End: chunk.End})
+			texts = append(texts, PrepareTextForEmbedding(item.Name, item.Description[chunk.Start:chunk.End]))
+		}
+
+		return parts, texts, textHash(text)
+	}
+
+	keywords := PathKeywords(item.Path)
+
+	if strings.TrimSpace(item.Name) != "" {
+		parts = append(parts, entities.EmbeddingChunk{Field: entities.EmbeddingFieldName})
+		texts = append(texts, item.Name)
+	}
+	if strings.TrimSpace(item.Description) != "" {
+		for _, chunk := range chunks {
+			part := entities.EmbeddingChunk{Field: entities.EmbeddingFieldDescription}
+			if len(chunks) > 1 {
+				part.Start, part.End = chunk.Start, chunk.End
+			}
+			parts = append(parts, part)
+			texts = append(texts, item.Description[chunk.Start:chunk.End])
+		}
+	}
+	if keywords != "" {
+		parts = append(parts, entities.EmbeddingChunk{Field: entities.EmbeddingFieldPath})
+		texts = append(texts, keywords)
+	}
+
+	// Nothing to tell the fields apart, embed the item as a whole
+	if len(parts) == 0 {
+		return []entities.EmbeddingChunk{{}}, []string{text}, textHash(text)
+	}
+
+	return parts, texts, textHash(fmt.Sprintf("%s\nPath: %s", text, keywords))
+}
@@ -1,109 +1,88 @@
 package handlers
 
 import (
-	"fmt"
-	"sort"
-
 	"MMDContent/internal/entities"
-	"MMDContent/internal/services/openai"
+	"MMDContent/internal/search"
+	"MMDContent/internal/services/embedding"
 	"MMDContent/internal/storage"
 )
 
 type Models struct {
-	client        openai.Client
-	modelsStorage *storage.Models
+	content *contentSearch[entities.Model]
 }
 
 func NewModels(
-	client openai.Client,
+	provider embedding.Provider,
+	index *search.VectorIndex,
+	keywords *search.KeywordIndex,
+	weights search.HybridWeights,
 	modelsStorage *storage.Models,
 ) *Models {
 	return &Models{
-		client:        client,
-		modelsStorage: modelsStorage,
+		content: newContentSearch(provider, index, keywords, weights, modelsStorage, modelFields),
 	}
 }
 
-// SearchModels searches models using semantic similarity with embeddings
-func (a *Models) SearchModels(query string, limit int) ([]entities.Model, error) {
-	if a.modelsStorage.IsEmpty() {
-		return []entities.Model{}, nil
-	}
-
-	// Generate embedding for the search query
-	queryEmbedding, err := a.client.GenerateEmbedding(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
-	}
-
-	// Calculate similarity scores for all models
-	type scoredModel struct {
-		model entities.Model
-		score float64
-	}
-
-	var scoredModels []scoredModel
-	for _, model := range a.modelsStorage.Get().Models {
-		if len(model.Embedding) == 0 {
-			// Skip models without embeddings
-			continue
-		}
-
-		similarity := CosineSimilarity(queryEmbedding, model.Embedding)
-		scoredModels = append(scoredModels, scoredModel{
-			model: model,
-			score: similarity,
-		})
-	}
-
-	// Sort by similarity score (descending)
-	sort.Slice(scoredModels, func(i, j int) bool {
-		return scoredModels[i].score > scoredModels[j].score
-	})
+// SearchModels searches models using semantic similarity with embeddings,
+// or by keywords when no embedding provider is configured.
+// The query can contain filters, see search.Query.
+func (a *Models) SearchModels(query string, limit int, minScore float64) ([]entities.SearchResult[entities.Model], error) {
+	return a.content.semanticSearch(query, limit, minScore)
+}
 
-	// Return top results
-	if limit <= 0 || limit > len(scoredModels) {
-		limit = len(scoredModels)
-	}
+// HybridSearchModels combines keyword and semantic rankings, so that a model whose name
+// is typed verbatim ranks first while similar descriptions still match
+func (a *Models) HybridSearchModels(query string, limit int, minScore float64) ([]entities.SearchResult[entities.Model], error) {
+	return a.content.hybridSearch(query, limit, minScore)
+}
 
-	results := make([]entities.Model, limit)
-	for i := 0; i < limit; i++ {
-		results[i] = scoredModels[i].model
-	}
+// SimilarModels returns the models most similar to a model, using its stored embedding
+// so that no API call is needed
+func (a *Models) SimilarModels(id string, limit int) ([]entities.SearchResult[entities.Model], error) {
+	return a.content.similarTo(entities.ContentTypeModel, id, limit)
+}
 
-	return results, nil
+// ModelsSimilarTo returns the models most similar to an item of any content type,
+// e.g. the models matching a stage, using its stored embedding
+func (a *Models) ModelsSimilarTo(contentType entities.ContentType, id string, limit int) ([]entities.SearchResult[entities.Model], error) {
+	return a.content.similarTo(contentType, id, limit)
 }
 
-// GetModels returns paginated models
-func (a *Models) GetModels(page, perPage int) entities.Pagination[entities.Model] {
-	if a.modelsStorage.IsEmpty() {
-		return entities.Pagination[entities.Model]{
-			Data:       []entities.Model{},
-			Total:      0,
-			Page:       page,
-			PerPage:    perPage,
-			TotalPages: 0,
-		}
-	}
+// KeywordSearchModels searches models by name, description and path with BM25 ranking,
+// without calling any embedding provider
+func (a *Models) KeywordSearchModels(query string, limit int, minScore float64) ([]entities.SearchResult[entities.Model], error) {
+	return a.content.keywordQuery(query, limit, minScore)
+}
 
-	return a.modelsStorage.GetPaginatedModels(page, perPage)
+// GetModels returns paginated models matching the query, all of them when it is empty
+func (a *Models) GetModels(page, perPage int, query string) (entities.Pagination[entities.Model], error) {
+	return a.content.paginated(page, perPage, query)
 }
 
 // GetAllModels returns all models without pagination
 func (a *Models) GetAllModels() []entities.Model {
-	if a.modelsStorage.IsEmpty() {
-		return []entities.Model{}
-	}
+	return a.content.all()
+}
 
-	return a.modelsStorage.Get().Models
+// ExportModels returns every model matching the query, in catalog order
+func (a *Models) ExportModels(query string) ([]entities.Model, error) {
+	return a.content.export(query)
 }
 
-// RefreshModelsData re-parses and reloads the models data
-func (a *Models) RefreshModelsData() error {
-	err := a.modelsStorage.Refresh()
-	if err != nil {
-		return err
-	}
+// RefreshModelsData re-parses the models folder and reports the models added, updated, removed and renamed.
+// Most of the models missing from the folder are only removed with confirmRemovals.
+func (a *Models) RefreshModelsData(confirmRemovals bool) (entities.SyncReport, error) {
+	return a.content.refresh(confirmRemovals)
+}
 
-	return nil
+// modelFields returns the attributes of a model used by query filters
+func modelFields(model entities.Model) search.Fields {
+	return search.Fields{
+		ID:          model.ID,
+		Type:        entities.ContentTypeModel,
+		Name:        model.Name,
+		Description: model.Description,
+		Path:        model.OriginalPath,
+		Screenshots: len(model.Screenshots),
+	}
 }
@@ -1,109 +1,89 @@
 package handlers
 
 import (
-	"fmt"
-	"sort"
-
 	"MMDContent/internal/entities"
-	"MMDContent/internal/services/openai"
+	"MMDContent/internal/search"
+	"MMDContent/internal/services/embedding"
 	"MMDContent/internal/storage"
 )
 
 type Motions struct {
-	client         openai.Client
-	motionsStorage *storage.Motions
+	content *contentSearch[entities.Motion]
 }
 
 func NewMotions(
-	client openai.Client,
+	provider embedding.Provider,
+	index *search.VectorIndex,
+	keywords *search.KeywordIndex,
+	weights search.HybridWeights,
 	motionsStorage *storage.Motions,
 ) *Motions {
 	return &Motions{
-		client:         client,
-		motionsStorage: motionsStorage,
+		content: newContentSearch(provider, index, keywords, weights, motionsStorage, motionFields),
 	}
 }
 
-// SearchMotions searches motions using semantic similarity with embeddings
-func (a *Motions) SearchMotions(query string, limit int) ([]entities.Motion, error) {
-	if a.motionsStorage.IsEmpty() {
-		return []entities.Motion{}, nil
-	}
-
-	// Generate embedding for the search query
-	queryEmbedding, err := a.client.GenerateEmbedding(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
-	}
-
-	// Calculate similarity scores for all motions
-	type scoredMotion struct {
-		motion entities.Motion
-		score  float64
-	}
-
-	var scoredMotions []scoredMotion
-	for _, motion := range a.motionsStorage.Get().Motions {
-		if len(motion.Embedding) == 0 {
-			// Skip motions without embeddings
-			continue
-		}
-
-		similarity := CosineSimilarity(queryEmbedding, motion.Embedding)
-		scoredMotions = append(scoredMotions, scoredMotion{
-			motion: motion,
-			score:  similarity,
-		})
-	}
-
-	// Sort by similarity score (descending)
-	sort.Slice(scoredMotions, func(i, j int) bool {
-		return scoredMotions[i].score > scoredMotions[j].score
-	})
+// SearchMotions searches motions using semantic similarity with embeddings,
+// or by keywords when no embedding provider is configured.
+// The query can contain filters, see search.Query.
+func (a *Motions) SearchMotions(query string, limit int, minScore float64) ([]entities.SearchResult[entities.Motion], error) {
+	return a.content.semanticSearch(query, limit, minScore)
+}
 
-	// Return top results
-	if limit <= 0 || limit > len(scoredMotions) {
-		limit = len(scoredMotions)
-	}
+// HybridSearchMotions combines keyword and semantic rankings, so that a motion whose name
+// is typed verbatim ranks first while similar descriptions still match
+func (a *Motions) HybridSearchMotions(query string, limit int, minScore float64) ([]entities.SearchResult[entities.Motion], error) {
+	return a.content.hybridSearch(query, limit, minScore)
+}
 
-	results := make([]entities.Motion, limit)
-	for i := 0; i < limit; i++ {
-		results[i] = scoredMotions[i].motion
-	}
+// SimilarMotions returns the motions most similar to a motion, using its stored embedding
+// so that no API call is needed
+func (a *Motions) SimilarMotions(id string, limit int) ([]entities.SearchResult[entities.Motion], error) {
+	return a.content.similarTo(entities.ContentTypeMotion, id, limit)
+}
 
-	return results, nil
+// MotionsSimilarTo returns the motions most similar to an item of any content type,
+// e.g. the motions matching a stage, using its stored embedding
+func (a *Motions) MotionsSimilarTo(contentType entities.ContentType, id string, limit int) ([]entities.SearchResult[entities.Motion], error) {
+	return a.content.similarTo(contentType, id, limit)
 }
 
-// GetMotions returns paginated motions
-func (a *Motions) GetMotions(page, perPage int) entities.Pagination[entities.Motion] {
-	if a.motionsStorage.IsEmpty() {
-		return entities.Pagination[entities.Motion]{
-			Data:       []entities.Motion{},
-			Total:      0,
-			Page:       page,
-			PerPage:    perPage,
-			TotalPages: 0,
-		}
-	}
+// KeywordSearchMotions searches motions by name, description and path with BM25 ranking,
+// without calling any embedding provider
+func (a *Motions) KeywordSearchMotions(query string, limit int, minScore float64) ([]entities.SearchResult[entities.Motion], error) {
+	return a.content.keywordQuery(query, limit, minScore)
+}
 
-	return a.motionsStorage.GetPaginatedMotions(page, perPage)
+// GetMotions returns paginated motions matching the query, all of them when it is empty
+func (a *Motions) GetMotions(page, perPage int, query string) (entities.Pagination[entities.Motion], error) {
+	return a.content.paginated(page, perPage, query)
 }
 
 // GetAllMotions returns all motions without pagination
 func (a *Motions) GetAllMotions() []entities.Motion {
-	if a.motionsStorage.IsEmpty() {
-		return []entities.Motion{}
-	}
+	return a.content.all()
+}
 
-	return a.motionsStorage.Get().Motions
+// ExportMotions returns every motion matching the query, in catalog order
+func (a *Motions) ExportMotions(query string) ([]entities.Motion, error) {
+	return a.content.export(query)
 }
 
-// RefreshMotionsData re-parses and reloads the motions data
-func (a *Motions) RefreshMotionsData() error {
-	err := a.motionsStorage.Refresh()
-	if err != nil {
-		return err
-	}
+// RefreshMotionsData re-parses the motions folder and reports the motions added, updated, removed and renamed.
+// Most of the motions missing from the folder are only removed with confirmRemovals.
+func (a *Motions) RefreshMotionsData(confirmRemovals bool) (entities.SyncReport, error) {
+	return a.content.refresh(confirmRemovals)
+}
 
-	return nil
+// motionFields returns the attributes of a motion used by query filters
+func motionFields(motion entities.Motion) search.Fields {
+	return search.Fields{
+		ID:          motion.ID,
+		Type:        entities.ContentTypeMotion,
+		Name:        motion.Name,
+		Description: motion.Description,
+		Path:        motion.OriginalPath,
+		Screenshots: len(motion.Screenshots),
+		Videos:      len(motion.Video),
+	}
 }
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"MMDContent/internal/entities"
+	"MMDContent/internal/search"
+	"MMDContent/internal/services/embedding"
+)
+
+// ErrNoEmbedding is returned when looking for items similar to one that has no embedding
+var ErrNoEmbedding = errors.New("the item has no embedding of the current model, generate embeddings first")
+
+// latestSearch keeps track of the in-flight search so that starting a new one
+// cancels the previous, e.g. when the user keeps typing in the search box
+type latestSearch struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// start cancels the previous search and returns the context for the new one.
+// The returned function must be called once the search is over.
+func (l *latestSearch) start() (context.Context, context.CancelFunc) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cancel != nil {
+		l.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+
+	return ctx, cancel
+}
+
+// embedQuery generates the embedding of a search query. Starting a new search
+// cancels the previous one, which then fails with context.Canceled.
+func embedQuery(latest *latestSearch, provider embedding.Provider, query string) ([]float64, error) {
+	ctx, cancel := latest.start()
+	defer cancel()
+
+	queryEmbedding, err := provider.GenerateEmbedding(ctx, query)
+	if errors.Is(err, context.Canceled) {
+		return nil, fmt.Errorf("search cancelled by a newer search: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	return queryEmbedding, nil
+}
+
+// candidates returns how many hits a search must fetch to return limit results.
+// Filters drop hits afterwards, so every hit is fetched when the query has some.
+func candidates(q search.Query, limit int) int {
+	if q.Filter != nil {
+		return 0
+	}
+
+	return limit
+}
+
+// filteredHits returns a hit for every item matching the filters of a query without
+// free text, in catalog order since there is nothing to rank them by
+func filteredHits[T any](items []T, q search.Query, fields func(item T) search.Fields) []search.Hit {
+	hits := make([]search.Hit, 0)
+	for _, item := range items {
+		f := fields(item)
+		if q.Match(f) {
+			hits = append(hits, search.Hit{ID: f.ID, Score: 1})
+		}
+	}
+
+	return hits
+}
+
+// searchResults turns search hits into ranked results with highlighted snippets.
+// Hits of items not matching the query filters, scoring below minScore or no longer
+// in the catalog are dropped, and at most limit results are returned (all when limit <= 0).
+func searchResults[T any](
+	hits []search.Hit,
+	q search.Query,
+	limit int,
+	minScore float64,
+	find func(id string) (T, bool),
+	fields func(item T) search.Fields,
+) []entities.SearchResult[T] {
+	matches := matchHits(hits, q, limit, minScore, find, fields)
+	results := make([]entities.SearchResult[T], len(matches))
+	for i, match := range matches {
+		results[i] = match.result(q, i+1)
+	}
+
+	return results
+}
+
+// searchMatch is a hit whose item matches the query, snippets are only made for the
+// matches that end up in the results since they are the costly part
+type searchMatch[T any] struct {
+	item   T
+	fields search.Fields
+	hit    search.Hit
+}
+
+// matchHits keeps the hits of items in the catalog matching the query filters and
+// scoring at least minScore, at most limit of them (all when limit <= 0)
+func matchHits[T any](
+	hits []search.Hit,
+	q search.Query,
+	limit int,
+	minScore float64,
+	find func(id string) (T, bool),
+	fields func(item T) search.Fields,
+) []searchMatch[T] {
+	matches := make([]searchMatch[T], 0, len(hits))
+	for _, hit := range hits {
+		if limit > 0 && len(matches) == limit {
+			break
+		}
+		if hit.Score < minScore {
+			continue
+		}
+
+		item, ok := find(hit.ID)
+		if !ok {
+			continue
+		}
+
+		f := fields(item)
+		if !q.Match(f) {
+			continue
+		}
+
+		matches = append(matches, searchMatch[T]{item: item, fields: f, hit: hit})
+	}
+
+	return matches
+}
+
+// result makes the search result of a match, with its highlighted snippets
+func (m searchMatch[T]) result(q search.Query, rank int) entities.SearchResult[T] {
+	return entities.SearchResult[T]{
+		Item:     m.item,
+		Score:    m.hit.Score,
+		Rank:     rank,
+		Snippets: search.Snippets(m.fields.Description, q.Text()),
+		Passage:  passage(m.fields.Description, m.hit),
+	}
+}
+
+// passage returns the part of the description a hit points at, if any. The offsets
+// come from the stored embedding and no longer apply if the description changed since.
+func passage(description string, hit search.Hit) string {
+	if hit.End <= hit.Start || hit.End > len(description) {
+		return ""
+	}
+	if !utf8.RuneStart(description[hit.Start]) || (hit.End < len(description) && !utf8.RuneStart(description[hit.End])) {
+		return ""
+	}
+
+	return strings.TrimSpace(description[hit.Start:hit.End])
+}
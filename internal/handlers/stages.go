@@ -1,109 +1,88 @@
 package handlers
 
 import (
-	"fmt"
-	"sort"
-
 	"MMDContent/internal/entities"
-	"MMDContent/internal/services/openai"
+	"MMDContent/internal/search"
+	"MMDContent/internal/services/embedding"
 	"MMDContent/internal/storage"
 )
 
 type Stages struct {
-	client        openai.Client
-	stagesStorage *storage.Stages
+	content *contentSearch[entities.Stage]
 }
 
 func NewStages(
-	client openai.Client,
+	provider embedding.Provider,
+	index *search.VectorIndex,
+	keywords *search.KeywordIndex,
+	weights search.HybridWeights,
 	stagesStorage *storage.Stages,
 ) *Stages {
 	return &Stages{
-		client:        client,
-		stagesStorage: stagesStorage,
+		content: newContentSearch(provider, index, keywords, weights, stagesStorage, stageFields),
 	}
 }
 
-// SearchStages searches stages using semantic similarity with embeddings
-func (a *Stages) SearchStages(query string, limit int) ([]entities.Stage, error) {
-	if a.stagesStorage.IsEmpty() {
-		return []entities.Stage{}, nil
-	}
-
-	// Generate embedding for the search query
-	queryEmbedding, err := a.client.GenerateEmbedding(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
-	}
-
-	// Calculate similarity scores for all stages
-	type scoredStage struct {
-		stage entities.Stage
-		score float64
-	}
-
-	var scoredStages []scoredStage
-	for _, stage := range a.stagesStorage.Get().Stages {
-		if len(stage.Embedding) == 0 {
-			// Skip stages without embeddings
-			continue
-		}
+// SearchStages searches stages using semantic similarity with embeddings,
+// or by keywords when no embedding provider is configured.
+// The query can contain filters, see search.Query.
+func (a *Stages) SearchStages(query string, limit int, minScore float64) ([]entities.SearchResult[entities.Stage], error) {
+	return a.content.semanticSearch(query, limit, minScore)
+}
 
-		similarity := CosineSimilarity(queryEmbedding, stage.Embedding)
-		scoredStages = append(scoredStages, scoredStage{
-			stage: stage,
-			score: similarity,
-		})
-	}
+// HybridSearchStages combines keyword and semantic rankings, so that a stage whose name
+// is typed verbatim ranks first while similar descriptions still match
+func (a *Stages) HybridSearchStages(query string, limit int, minScore float64) ([]entities.SearchResult[entities.Stage], error) {
+	return a.content.hybridSearch(query, limit, minScore)
+}
 
-	// Sort by similarity score (descending)
-	sort.Slice(scoredStages, func(i, j int) bool {
-		return scoredStages[i].score > scoredStages[j].score
-	})
+// SimilarStages returns the stages most similar to a stage, using its stored embedding
+// so that no API call is needed
+func (a *Stages) SimilarStages(id string, limit int) ([]entities.SearchResult[entities.Stage], error) {
+	return a.content.similarTo(entities.ContentTypeStage, id, limit)
+}
 
-	// Return top results
-	if limit <= 0 || limit > len(scoredStages) {
-		limit = len(scoredStages)
-	}
+// StagesSimilarTo returns the stages most similar to an item of any content type,
+// e.g. the stages matching a stage, using its stored embedding
+func (a *Stages) StagesSimilarTo(contentType entities.ContentType, id string, limit int) ([]entities.SearchResult[entities.Stage], error) {
+	return a.content.similarTo(contentType, id, limit)
+}
 
-	results := make([]entities.Stage, limit)
-	for i := 0; i < limit; i++ {
-		results[i] = scoredStages[i].stage
-	}
+// KeywordSearchStages searches stages by name, description and path with BM25 ranking,
+// without calling any embedding provider
+func (a *Stages) KeywordSearchStages(query string, limit int, minScore float64) ([]entities.SearchResult[entities.Stage], error) {
+	return a.content.keywordQuery(query, limit, minScore)
+}
 
-	return results, nil
+// GetStages returns paginated stages matching the query, all of them when it is empty
+func (a *Stages) GetStages(page, perPage int, query string) (entities.Pagination[entities.Stage], error) {
+	return a.content.paginated(page, perPage, query)
 }
 
 // GetAllStages returns all stages without pagination
 func (a *Stages) GetAllStages() []entities.Stage {
-	if a.stagesStorage.IsEmpty() {
-		return []entities.Stage{}
-	}
-
-	return a.stagesStorage.Get().Stages
+	return a.content.all()
 }
 
-// GetStages returns paginated stages
-func (a *Stages) GetStages(page, perPage int) entities.Pagination[entities.Stage] {
-	if a.stagesStorage.IsEmpty() {
-		return entities.Pagination[entities.Stage]{
-			Data:       []entities.Stage{},
-			Total:      0,
-			Page:       page,
-			PerPage:    perPage,
-			TotalPages: 0,
-		}
-	}
+// ExportStages returns every stage matching the query, in catalog order
+func (a *Stages) ExportStages(query string) ([]entities.Stage, error) {
+	return a.content.export(query)
+}
 
-	return a.stagesStorage.GetPaginatedStages(page, perPage)
+// RefreshStagesData re-parses the stages folder and reports the stages added, updated, removed and renamed.
+// Most of the stages missing from the folder are only removed with confirmRemovals.
+func (a *Stages) RefreshStagesData(confirmRemovals bool) (entities.SyncReport, error) {
+	return a.content.refresh(confirmRemovals)
 }
 
-// RefreshStagesData re-parses and reloads the stages data
-func (a *Stages) RefreshStagesData() error {
-	err := a.stagesStorage.Refresh()
-	if err != nil {
-		return err
+// stageFields returns the attributes of a stage used by query filters
+func stageFields(stage entities.Stage) search.Fields {
+	return search.Fields{
+		ID:          stage.ID,
+		Type:        entities.ContentTypeStage,
+		Name:        stage.Name,
+		Description: stage.Description,
+		Path:        stage.OriginalPath,
+		Screenshots: len(stage.Screenshots),
 	}
-
-	return nil
 }
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"MMDContent/internal/entities"
+	"MMDContent/internal/services/openai"
+	"MMDContent/internal/storage"
+)
+
+// ErrBudgetExceeded stops embedding generation once the monthly budget is spent
+var ErrBudgetExceeded = errors.New("the monthly embedding budget is spent")
+
+// usageRunKey tags the context of a generation run so that its requests are counted in the run
+type usageRunKey struct{}
+
+// Usage shows the spend on embeddings to the frontend
+type Usage struct {
+	meter *UsageMeter
+}
+
+func NewUsage(meter *UsageMeter) *Usage {
+	return &Usage{
+		meter: meter,
+	}
+}
+
+// GetUsage returns the spend of the current month, its days and the latest generation runs
+func (a *Usage) GetUsage() (entities.UsageSummary, error) {
+	return a.meter.summary(), nil
+}
+
+// UsageMeter records the tokens billed for embeddings and enforces the monthly budget
+type UsageMeter struct {
+	usageStorage *storage.Usage
+	budget       float64
+	price        float64
+}
+
+// NewUsageMeter tracks the spend on embeddings. budget is the monthly limit in USD, 0 for none.
+// price overrides the price per million tokens of every model, 0 uses the OpenAI list prices.
+func NewUsageMeter(
+	usageStorage *storage.Usage,
+	budget float64,
+	price float64,
+) *UsageMeter {
+	return &UsageMeter{
+		usageStorage: usageStorage,
+		budget:       budget,
+		price:        price,
+	}
+}
+
+func (u *UsageMeter) summary() entities.UsageSummary {
+	month := time.Now().Format("2006-01")
+	summary := entities.UsageSummary{
+		Month:  month,
+		Budget: u.budget,
+		Days:   u.usageStorage.Days(month),
+		Runs:   u.usageStorage.Runs(),
+	}
+
+	for _, day := range summary.Days {
+		summary.Tokens += day.Tokens
+		summary.Cost += day.Cost
+	}
+
+	if u.budget > 0 {
+		summary.Remaining = max(0, u.budget-summary.Cost)
+		summary.BudgetExceeded = summary.Cost >= u.budget
+	}
+
+	return summary
+}
+
+// Record adds the tokens billed for a request to today and to the run of the context, if any.
+// It is given to the embedding provider, which calls it after every request.
+func (u *UsageMeter) Record(ctx context.Context, model string, tokens int) {
+	run, _ := ctx.Value(usageRunKey{}).(time.Time)
+
+	err := u.usageStorage.Record(time.Now().Format("2006-01-02"), run, model, tokens, u.cost(model, tokens))
+	if err != nil {
+		slog.Error("error saving embedding usage", "error", err)
+	}
+}
+
+// allow returns ErrBudgetExceeded when spending the tokens would go over the monthly budget
+func (u *UsageMeter) allow(model string, tokens int) error {
+	if u.budget <= 0 {
+		return nil
+	}
+
+	spent := 0.0
+	for _, day := range u.usageStorage.Days(time.Now().Format("2006-01")) {
+		spent += day.Cost
+	}
+
+	if spent >= u.budget || spent+u.cost(model, tokens) > u.budget {
+		return fmt.Errorf("%w: $%.2f of $%.2f", ErrBudgetExceeded, spent, u.budget)
+	}
+
+	return nil
+}
+
+// cost estimates the price in USD of tokens, unknown models are free (e.g. local ones)
+func (u *UsageMeter) cost(model string, tokens int) float64 {
+	price := u.price
+	if price <= 0 {
+		price = openai.PricesPerMillionTokens[model]
+	}
+
+	return float64(tokens) * price / 1e6
+}
+
+// withUsageRun tags requests made with the context as part of the run started at startedAt
+func withUsageRun(ctx context.Context, startedAt time.Time) context.Context {
+	return context.WithValue(ctx, usageRunKey{}, startedAt)
+}
@@ -0,0 +1,72 @@
+package quantize
+
+import "math"
+
+// float16Values decodes every half precision value, scoring then costs a lookup per dimension
+var float16Values = func() []float32 {
+	values := make([]float32, 1<<16)
+	for h := range values {
+		values[h] = fromFloat16(uint16(h))
+	}
+	return values
+}()
+
+// toFloat16 converts to IEEE 754 half precision, rounding to nearest
+func toFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exponent := int(bits>>23&0xff) - 127 + 15
+	mantissa := bits & 0x7fffff
+
+	switch {
+	case bits>>23&0xff == 0xff:
+		// Infinity or NaN
+		if mantissa != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exponent >= 0x1f:
+		// Too large, infinity
+		return sign | 0x7c00
+	case exponent <= 0:
+		// Subnormal or too small
+		if exponent < -10 {
+			return sign
+		}
+		mantissa |= 0x800000
+		shift := uint32(14 - exponent)
+		half := uint16(mantissa >> shift)
+		if mantissa>>(shift-1)&1 != 0 {
+			half++
+		}
+		return sign | half
+	}
+
+	half := sign | uint16(exponent)<<10 | uint16(mantissa>>13)
+	if mantissa&0x1000 != 0 {
+		// A carry into the exponent is still the correctly rounded value
+		half++
+	}
+
+	return half
+}
+
+func fromFloat16(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exponent := uint32(h>>10) & 0x1f
+	mantissa := uint32(h & 0x3ff)
+
+	switch exponent {
+	case 0:
+		// Zero or subnormal
+		value := float32(mantissa) / (1 << 24)
+		if sign != 0 {
+			value = -value
+		}
+		return value
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mantissa<<13)
+	}
+
+	return math.Float32frombits(sign | (exponent+127-15)<<23 | mantissa<<13)
+}
@@ -0,0 +1,90 @@
+package quantize
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToFloat16(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float32
+		want  uint16
+	}{
+		{"zero", 0, 0x0000},
+		{"negative zero", float32(math.Copysign(0, -1)), 0x8000},
+		{"one", 1, 0x3c00},
+		{"minus two", -2, 0xc000},
+		{"largest normal", 65504, 0x7bff},
+		{"smallest normal", 0x1p-14, 0x0400},
+		{"largest subnormal", 0x3ffp-24, 0x03ff},
+		{"smallest subnormal", 0x1p-24, 0x0001},
+		{"negative subnormal", -0x1p-24, 0x8001},
+		{"too small", 0x1p-26, 0x0000},
+		{"negative too small", -0x1p-26, 0x8000},
+		{"rounds up to infinity", 65520, 0x7c00},
+		{"overflow", 1e6, 0x7c00},
+		{"negative overflow", -1e6, 0xfc00},
+		{"infinity", float32(math.Inf(1)), 0x7c00},
+		{"negative infinity", float32(math.Inf(-1)), 0xfc00},
+		{"rounds to nearest", 1 + 0x1p-11 + 0x1p-12, 0x3c01},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toFloat16(tt.value); got != tt.want {
+				t.Errorf("toFloat16(%g) = %#04x, want %#04x", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToFloat16NaN(t *testing.T) {
+	h := toFloat16(float32(math.NaN()))
+	if h&0x7c00 != 0x7c00 || h&0x3ff == 0 {
+		t.Fatalf("toFloat16(NaN) = %#04x, not a NaN", h)
+	}
+	if value := fromFloat16(h); !math.IsNaN(float64(value)) {
+		t.Fatalf("fromFloat16(%#04x) = %g, want NaN", h, value)
+	}
+}
+
+// Every half precision value converts to single precision and back unchanged
+func TestFloat16RoundTrip(t *testing.T) {
+	for i := range 1 << 16 {
+		h := uint16(i)
+		value := fromFloat16(h)
+		if math.IsNaN(float64(value)) {
+			if h&0x7c00 != 0x7c00 || h&0x3ff == 0 {
+				t.Fatalf("fromFloat16(%#04x) = NaN", h)
+			}
+			continue
+		}
+
+		if got := toFloat16(value); got != h {
+			t.Fatalf("toFloat16(fromFloat16(%#04x)) = %#04x (%g)", h, got, value)
+		}
+		if float16Values[h] != value {
+			t.Fatalf("float16Values[%#04x] = %g, want %g", h, float16Values[h], value)
+		}
+	}
+}
+
+func TestEncodeInt8(t *testing.T) {
+	values := []float32{0.5, -1, 0.25, 0}
+	v := Encode(values, Int8)
+
+	if v.Scale != 1.0/127 {
+		t.Fatalf("scale = %g, want %g", v.Scale, 1.0/127)
+	}
+	for i, value := range values {
+		if diff := math.Abs(float64(v.At(i) - value)); diff > float64(v.Scale)/2 {
+			t.Errorf("At(%d) = %g, want %g within half a step", i, v.At(i), value)
+		}
+	}
+
+	zero := Encode([]float32{0, 0}, Int8)
+	if zero.Scale != 0 || zero.At(0) != 0 || zero.Len() != 2 {
+		t.Errorf("zero vector encoded as %+v", zero)
+	}
+}
@@ -0,0 +1,185 @@
+package quantize
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Encoding is how the values of a vector are stored
+type Encoding uint8
+
+const (
+	// Float32 keeps full single precision values, 4 bytes each
+	Float32 Encoding = iota
+	// Float16 keeps half precision values, 2 bytes each
+	Float16
+	// Int8 keeps values as 1 byte integers multiplied by a scale shared by the vector
+	Int8
+)
+
+// ParseEncoding parses float32, float16 or int8, an empty string is float32
+func ParseEncoding(s string) (Encoding, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "float32":
+		return Float32, nil
+	case "float16":
+		return Float16, nil
+	case "int8":
+		return Int8, nil
+	default:
+		return Float32, fmt.Errorf("unknown vector encoding %q, expected float32, float16 or int8", s)
+	}
+}
+
+func (e Encoding) String() string {
+	switch e {
+	case Float32:
+		return "float32"
+	case Float16:
+		return "float16"
+	case Int8:
+		return "int8"
+	default:
+		return fmt.Sprintf("encoding(%d)", e)
+	}
+}
+
+// Vector is a vector in one of the encodings, only the slice of its encoding is set.
+// The fields are exported so that vectors can be persisted with gob.
+type Vector struct {
+	Encoding Encoding
+	// Scale converts Int8 values back: value = Int8[i] * Scale
+	Scale   float32
+	Float32 []float32
+	Float16 []uint16
+	Int8    []int8
+}
+
+// Encode converts values to the encoding. Int8 uses the largest absolute value as
+// the scale, so every vector keeps the full precision of the encoding.
+func Encode(values []float32, encoding Encoding) Vector {
+	v := Vector{Encoding: encoding}
+
+	switch encoding {
+	case Float16:
+		v.Float16 = make([]uint16, len(values))
+		for i, value := range values {
+			v.Float16[i] = toFloat16(value)
+		}
+	case Int8:
+		var maxAbs float32
+		for _, value := range values {
+			maxAbs = max(maxAbs, float32(math.Abs(float64(value))))
+		}
+
+		v.Int8 = make([]int8, len(values))
+		if maxAbs == 0 {
+			return v
+		}
+
+		v.Scale = maxAbs / 127
+		for i, value := range values {
+			v.Int8[i] = int8(max(-127, min(127, math.Round(float64(value/v.Scale)))))
+		}
+	default:
+		v.Encoding = Float32
+		v.Float32 = append([]float32(nil), values...)
+	}
+
+	return v
+}
+
+// Len returns the number of dimensions
+func (v Vector) Len() int {
+	switch v.Encoding {
+	case Float16:
+		return len(v.Float16)
+	case Int8:
+		return len(v.Int8)
+	default:
+		return len(v.Float32)
+	}
+}
+
+// At returns the value of dimension i
+func (v Vector) At(i int) float32 {
+	switch v.Encoding {
+	case Float16:
+		return float16Values[v.Float16[i]]
+	case Int8:
+		return float32(v.Int8[i]) * v.Scale
+	default:
+		return v.Float32[i]
+	}
+}
+
+// Floats decodes the vector
+func (v Vector) Floats() []float32 {
+	if v.Encoding == Float32 {
+		return v.Float32
+	}
+
+	values := make([]float32, v.Len())
+	for i := range values {
+		values[i] = v.At(i)
+	}
+
+	return values
+}
+
+// Dot returns the dot product with a query of the same size, without decoding the vector
+func (v Vector) Dot(q []float32) float64 {
+	var sum float64
+
+	switch v.Encoding {
+	case Float16:
+		for i, h := range v.Float16 {
+			sum += float64(q[i]) * float64(float16Values[h])
+		}
+	case Int8:
+		for i, n := range v.Int8 {
+			sum += float64(q[i]) * float64(n)
+		}
+		sum *= float64(v.Scale)
+	default:
+		for i, value := range v.Float32 {
+			sum += float64(q[i]) * float64(value)
+		}
+	}
+
+	return sum
+}
+
+// DotVector returns the dot product of two vectors of the same size. Two int8
+// vectors are multiplied as integers.
+func (v Vector) DotVector(w Vector) float64 {
+	switch {
+	case v.Encoding == Int8 && w.Encoding == Int8:
+		var sum int64
+		for i, n := range v.Int8 {
+			sum += int64(n) * int64(w.Int8[i])
+		}
+		return float64(sum) * float64(v.Scale) * float64(w.Scale)
+	case w.Encoding == Float32:
+		return v.Dot(w.Float32)
+	default:
+		var sum float64
+		for i := range v.Len() {
+			sum += float64(v.At(i)) * float64(w.At(i))
+		}
+		return sum
+	}
+}
+
+// Size returns the number of bytes taken by the values
+func (v Vector) Size() int {
+	switch v.Encoding {
+	case Float16:
+		return 2 * len(v.Float16)
+	case Int8:
+		return len(v.Int8)
+	default:
+		return 4 * len(v.Float32)
+	}
+}
@@ -0,0 +1,74 @@
+package search
+
+import "sort"
+
+const (
+	// rrfK dampens the advantage of the very first ranks in reciprocal rank fusion
+	rrfK = 60
+
+	// minFusionCandidates is the number of hits fetched from each list for small limits,
+	// an item ranked a bit lower in both lists should still be able to win
+	minFusionCandidates = 50
+)
+
+// HybridWeights sets how much keyword and semantic rankings count in a hybrid search
+type HybridWeights struct {
+	Keyword  float64
+	Semantic float64
+}
+
+// DefaultHybridWeights favors neither keyword nor semantic matches
+var DefaultHybridWeights = HybridWeights{Keyword: 1, Semantic: 1}
+
+// FusionCandidates returns how many hits to fetch from each list to fuse limit results
+func FusionCandidates(limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+
+	return max(limit, minFusionCandidates)
+}
+
+// Hybrid fuses keyword and semantic hits with weighted reciprocal rank fusion
+// and returns the k best, or all of them when k <= 0. Scores of the original
+// lists are not comparable, so only ranks are used. Fused scores are scaled so
+// that an item ranked first in both lists scores 1. Passages of the semantic
+// hits are kept.
+func Hybrid(keyword, semantic []Hit, weights HybridWeights, k int) []Hit {
+	best := (weights.Keyword + weights.Semantic) / (rrfK + 1)
+	if best <= 0 {
+		return []Hit{}
+	}
+
+	scores := make(map[string]float64, len(keyword)+len(semantic))
+	passages := make(map[string]Hit, len(semantic))
+	for rank, hit := range keyword {
+		scores[hit.ID] += weights.Keyword / float64(rrfK+rank+1) / best
+	}
+	for rank, hit := range semantic {
+		scores[hit.ID] += weights.Semantic / float64(rrfK+rank+1) / best
+		passages[hit.ID] = hit
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		if score > 0 {
+			// Keep the passage that matched the semantic search
+			passage := passages[id]
+			hits = append(hits, Hit{ID: id, Score: score, Start: passage.Start, End: passage.End})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	if k > 0 && k < len(hits) {
+		hits = hits[:k]
+	}
+
+	return hits
+}
@@ -0,0 +1,370 @@
+package search
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"MMDContent/internal/quantize"
+)
+
+const (
+	// hnswM is the number of neighbors per node on upper layers, twice as many on layer 0
+	hnswM              = 16
+	hnswEfConstruction = 200
+	hnswEfSearch       = 100
+)
+
+// Hit is a search result: the ID of an item and its similarity to the query.
+// Start and End are the byte offsets of the passage of the description that
+// matched, both 0 when the whole item did.
+type Hit struct {
+	ID    string
+	Score float64
+	Start int
+	End   int
+}
+
+type hnswNode struct {
+	Label     string
+	Key       string
+	Vector    quantize.Vector
+	Neighbors [][]int32
+	Deleted   bool
+}
+
+// HNSW is an approximate nearest-neighbor index (Hierarchical Navigable Small World graph)
+// using cosine similarity. Vectors are normalized on insertion so similarity is a dot product,
+// then stored in the encoding of the graph and scored without being decoded.
+// Removed items are only marked as deleted, the graph is rebuilt once they dominate it.
+type HNSW struct {
+	Encoding   quantize.Encoding
+	Dimensions int
+	Entry      int32
+	MaxLevel   int
+	Nodes      []hnswNode
+
+	byLabel map[string]int32
+	live    int
+	rng     *rand.Rand
+}
+
+func NewHNSW(encoding quantize.Encoding) *HNSW {
+	h := &HNSW{Encoding: encoding, Entry: -1}
+	h.init()
+	return h
+}
+
+// init rebuilds the fields that are not persisted
+func (h *HNSW) init() {
+	h.byLabel = make(map[string]int32, len(h.Nodes))
+	h.live = 0
+	for i, node := range h.Nodes {
+		if node.Deleted {
+			continue
+		}
+		h.byLabel[node.Label] = int32(i)
+		h.live++
+	}
+	h.rng = rand.New(rand.NewPCG(uint64(len(h.Nodes)), 0x9e3779b97f4a7c15))
+}
+
+// Len returns the number of live items
+func (h *HNSW) Len() int {
+	return h.live
+}
+
+// Key returns the version key stored with an item, used to detect changed vectors
+func (h *HNSW) Key(label string) (string, bool) {
+	i, ok := h.byLabel[label]
+	if !ok {
+		return "", false
+	}
+
+	return h.Nodes[i].Key, true
+}
+
+// Labels returns the IDs of all live items
+func (h *HNSW) Labels() []string {
+	labels := make([]string, 0, h.live)
+	for label := range h.byLabel {
+		labels = append(labels, label)
+	}
+
+	return labels
+}
+
+// Fragmented reports whether deleted nodes outnumber live ones and the graph should be rebuilt
+func (h *HNSW) Fragmented() bool {
+	return len(h.Nodes)-h.live > h.live
+}
+
+// Insert adds an item, replacing the previous vector of the same label
+func (h *HNSW) Insert(label, key string, values []float32) {
+	if h.Dimensions == 0 {
+		h.Dimensions = len(values)
+	}
+	if len(values) != h.Dimensions {
+		return
+	}
+
+	h.Delete(label)
+
+	// The new node is linked using its exact values, it is stored encoded
+	vector := normalize(values)
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) / math.Log(hnswM)))
+	id := int32(len(h.Nodes))
+	h.Nodes = append(h.Nodes, hnswNode{
+		Label:     label,
+		Key:       key,
+		Vector:    quantize.Encode(vector, h.Encoding),
+		Neighbors: make([][]int32, level+1),
+	})
+	h.byLabel[label] = id
+	h.live++
+
+	if h.Entry < 0 {
+		h.Entry = id
+		h.MaxLevel = level
+		return
+	}
+
+	current := h.Entry
+	for l := h.MaxLevel; l > level; l-- {
+		current = h.greedy(vector, current, l)
+	}
+
+	for l := min(level, h.MaxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(vector, current, hnswEfConstruction, l)
+		neighbors := closest(candidates, hnswM)
+
+		h.Nodes[id].Neighbors[l] = neighbors
+		for _, neighbor := range neighbors {
+			h.link(neighbor, id, l)
+		}
+		current = candidates[0].node
+	}
+
+	if level > h.MaxLevel {
+		h.MaxLevel = level
+		h.Entry = id
+	}
+}
+
+// Delete marks the item as removed
+func (h *HNSW) Delete(label string) {
+	i, ok := h.byLabel[label]
+	if !ok {
+		return
+	}
+
+	h.Nodes[i].Deleted = true
+	delete(h.byLabel, label)
+	h.live--
+}
+
+// Search returns the k items most similar to the query, best first.
+// When k covers (nearly) every item, an exact scan is cheaper and complete.
+func (h *HNSW) Search(query []float64, k int) []Hit {
+	if h.live == 0 || len(query) != h.Dimensions {
+		return []Hit{}
+	}
+
+	q := make([]float32, len(query))
+	for i, value := range query {
+		q[i] = float32(value)
+	}
+	q = normalize(q)
+
+	if k <= 0 || k >= h.live/2 {
+		return h.scan(q, k)
+	}
+
+	current := h.Entry
+	for l := h.MaxLevel; l > 0; l-- {
+		current = h.greedy(q, current, l)
+	}
+
+	// Deleted nodes are traversed but not returned, widen the beam to compensate
+	ef := max(hnswEfSearch, k) * len(h.Nodes) / h.live
+	candidates := h.searchLayer(q, current, ef, 0)
+
+	hits := make([]Hit, 0, k)
+	for _, c := range candidates {
+		if h.Nodes[c.node].Deleted {
+			continue
+		}
+		hits = append(hits, Hit{ID: h.Nodes[c.node].Label, Score: 1 - c.distance})
+		if len(hits) == k {
+			break
+		}
+	}
+
+	return hits
+}
+
+func (h *HNSW) scan(q []float32, k int) []Hit {
+	hits := make([]Hit, 0, h.live)
+	for _, node := range h.Nodes {
+		if node.Deleted {
+			continue
+		}
+		hits = append(hits, Hit{ID: node.Label, Score: node.Vector.Dot(q)})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+
+	if k > 0 && k < len(hits) {
+		hits = hits[:k]
+	}
+
+	return hits
+}
+
+// greedy walks a layer towards the node closest to the query
+func (h *HNSW) greedy(q []float32, current int32, level int) int32 {
+	best := h.distance(q, current)
+	for changed := true; changed; {
+		changed = false
+		for _, neighbor := range h.Nodes[current].Neighbors[level] {
+			if d := h.distance(q, neighbor); d < best {
+				best = d
+				current = neighbor
+				changed = true
+			}
+		}
+	}
+
+	return current
+}
+
+// searchLayer runs a beam search of width ef on a layer and returns the candidates sorted by distance
+func (h *HNSW) searchLayer(q []float32, entry int32, ef int, level int) []hnswCandidate {
+	visited := make(map[int32]struct{}, ef*4)
+	visited[entry] = struct{}{}
+
+	first := hnswCandidate{node: entry, distance: h.distance(q, entry)}
+	toVisit := &candidateHeap{items: []hnswCandidate{first}}
+	found := &candidateHeap{items: []hnswCandidate{first}, farthestFirst: true}
+
+	for toVisit.Len() > 0 {
+		c := heap.Pop(toVisit).(hnswCandidate)
+		if c.distance > found.top().distance && found.Len() >= ef {
+			break
+		}
+
+		for _, neighbor := range h.Nodes[c.node].Neighbors[level] {
+			if _, ok := visited[neighbor]; ok {
+				continue
+			}
+			visited[neighbor] = struct{}{}
+
+			d := h.distance(q, neighbor)
+			if found.Len() < ef || d < found.top().distance {
+				heap.Push(toVisit, hnswCandidate{node: neighbor, distance: d})
+				heap.Push(found, hnswCandidate{node: neighbor, distance: d})
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	result := found.items
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].distance < result[j].distance
+	})
+
+	return result
+}
+
+// link adds target to the neighbors of node, keeping only the closest when the list is full
+func (h *HNSW) link(node, target int32, level int) {
+	limit := hnswM
+	if level == 0 {
+		limit = 2 * hnswM
+	}
+
+	neighbors := append(h.Nodes[node].Neighbors[level], target)
+	if len(neighbors) > limit {
+		candidates := make([]hnswCandidate, len(neighbors))
+		for i, neighbor := range neighbors {
+			candidates[i] = hnswCandidate{node: neighbor, distance: 1 - h.Nodes[node].Vector.DotVector(h.Nodes[neighbor].Vector)}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			return candidates[i].distance < candidates[j].distance
+		})
+		neighbors = closest(candidates, limit)
+	}
+
+	h.Nodes[node].Neighbors[level] = neighbors
+}
+
+func (h *HNSW) distance(q []float32, node int32) float64 {
+	return 1 - h.Nodes[node].Vector.Dot(q)
+}
+
+type hnswCandidate struct {
+	node     int32
+	distance float64
+}
+
+// closest returns the first n nodes of candidates sorted by distance
+func closest(candidates []hnswCandidate, n int) []int32 {
+	n = min(n, len(candidates))
+	nodes := make([]int32, n)
+	for i := range nodes {
+		nodes[i] = candidates[i].node
+	}
+
+	return nodes
+}
+
+// candidateHeap is a min-heap on distance, or a max-heap when farthestFirst is set
+type candidateHeap struct {
+	items         []hnswCandidate
+	farthestFirst bool
+}
+
+func (c *candidateHeap) Len() int { return len(c.items) }
+
+func (c *candidateHeap) Less(i, j int) bool {
+	if c.farthestFirst {
+		return c.items[i].distance > c.items[j].distance
+	}
+	return c.items[i].distance < c.items[j].distance
+}
+
+func (c *candidateHeap) Swap(i, j int) { c.items[i], c.items[j] = c.items[j], c.items[i] }
+
+func (c *candidateHeap) Push(x any) { c.items = append(c.items, x.(hnswCandidate)) }
+
+func (c *candidateHeap) Pop() any {
+	last := c.items[len(c.items)-1]
+	c.items = c.items[:len(c.items)-1]
+	return last
+}
+
+func (c *candidateHeap) top() hnswCandidate { return c.items[0] }
+
+func normalize(values []float32) []float32 {
+	var norm float64
+	for _, v := range values {
+		norm += float64(v) * float64(v)
+	}
+
+	normalized := make([]float32, len(values))
+	if norm == 0 {
+		return normalized
+	}
+
+	norm = math.Sqrt(norm)
+	for i, v := range values {
+		normalized[i] = float32(float64(v) / norm)
+	}
+
+	return normalized
+}
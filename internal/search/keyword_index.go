@@ -0,0 +1,281 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"MMDContent/internal/entities"
+)
+
+const (
+	// BM25 term frequency saturation and length normalization
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// Weights of the fields of a document, a match in the name counts the most
+	nameWeight        = 3.0
+	pathWeight        = 1.5
+	descriptionWeight = 1.0
+
+	// minPrefixLength is the shortest last query term expanded to the terms it
+	// prefixes, so that results show up while the user is still typing a word
+	minPrefixLength = 2
+)
+
+// KeywordSource is a catalog whose items can be keyword indexed
+type KeywordSource interface {
+	ContentType() entities.ContentType
+	// Version changes every time the items of the catalog change
+	Version() uint64
+	Embeddables() []entities.Embeddable
+}
+
+// KeywordIndex is an in-memory inverted index ranking items with BM25.
+// It works without any embedding provider. Each content type is synchronized
+// with its source before a search when the source changed, only re-indexing
+// the items whose text changed.
+type KeywordIndex struct {
+	mu      sync.Mutex
+	sources map[entities.ContentType]KeywordSource
+	indexes map[entities.ContentType]*bm25Index
+}
+
+func NewKeywordIndex(sources ...KeywordSource) *KeywordIndex {
+	x := &KeywordIndex{
+		sources: make(map[entities.ContentType]KeywordSource, len(sources)),
+		indexes: make(map[entities.ContentType]*bm25Index, len(sources)),
+	}
+
+	for _, source := range sources {
+		x.sources[source.ContentType()] = source
+		x.indexes[source.ContentType()] = newBM25Index()
+		x.sync(source.ContentType())
+	}
+
+	return x
+}
+
+// Search returns the k items of a content type best matching the query, best first.
+// All matching items are returned when k <= 0. Scores are relative to the best match,
+// which scores 1, since BM25 scores have no upper bound.
+func (x *KeywordIndex) Search(contentType entities.ContentType, query string, k int) []Hit {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if _, ok := x.indexes[contentType]; !ok {
+		return []Hit{}
+	}
+
+	x.sync(contentType)
+
+	return x.indexes[contentType].search(uniqueTerms(Tokenize(query)), k)
+}
+
+// sync re-indexes the items of a content type when its source changed
+func (x *KeywordIndex) sync(contentType entities.ContentType) {
+	source := x.sources[contentType]
+	index := x.indexes[contentType]
+
+	version := source.Version()
+	if index.synced && index.version == version {
+		return
+	}
+
+	index.sync(source.Embeddables())
+	index.version = version
+	index.synced = true
+}
+
+type bm25Document struct {
+	// text identifies the indexed version of the document
+	text   string
+	length float64
+	terms  map[string]float64
+}
+
+type bm25Index struct {
+	version uint64
+	synced  bool
+
+	documents   map[string]*bm25Document
+	postings    map[string]map[string]float64
+	totalLength float64
+}
+
+func newBM25Index() *bm25Index {
+	return &bm25Index{
+		documents: make(map[string]*bm25Document),
+		postings:  make(map[string]map[string]float64),
+	}
+}
+
+// sync adds new documents, re-indexes changed ones and removes the missing ones
+func (b *bm25Index) sync(documents []entities.Embeddable) {
+	seen := make(map[string]struct{}, len(documents))
+	for _, document := range documents {
+		seen[document.ID] = struct{}{}
+
+		text := document.Name + "\x00" + document.Path + "\x00" + document.Description
+		if indexed, ok := b.documents[document.ID]; ok && indexed.text == text {
+			continue
+		}
+
+		b.remove(document.ID)
+		b.add(document, text)
+	}
+
+	for id := range b.documents {
+		if _, ok := seen[id]; !ok {
+			b.remove(id)
+		}
+	}
+}
+
+func (b *bm25Index) add(document entities.Embeddable, text string) {
+	indexed := &bm25Document{
+		text:  text,
+		terms: make(map[string]float64),
+	}
+
+	fields := []struct {
+		text   string
+		weight float64
+	}{
+		{document.Name, nameWeight},
+		{document.Path, pathWeight},
+		{document.Description, descriptionWeight},
+	}
+	for _, field := range fields {
+		for _, term := range Tokenize(field.text) {
+			indexed.terms[term] += field.weight
+			indexed.length += field.weight
+		}
+	}
+
+	for term, frequency := range indexed.terms {
+		posting, ok := b.postings[term]
+		if !ok {
+			posting = make(map[string]float64)
+			b.postings[term] = posting
+		}
+		posting[document.ID] = frequency
+	}
+
+	b.documents[document.ID] = indexed
+	b.totalLength += indexed.length
+}
+
+func (b *bm25Index) remove(id string) {
+	indexed, ok := b.documents[id]
+	if !ok {
+		return
+	}
+
+	for term := range indexed.terms {
+		delete(b.postings[term], id)
+		if len(b.postings[term]) == 0 {
+			delete(b.postings, term)
+		}
+	}
+
+	delete(b.documents, id)
+	b.totalLength -= indexed.length
+}
+
+func (b *bm25Index) search(terms []string, k int) []Hit {
+	if len(terms) == 0 || len(b.documents) == 0 {
+		return []Hit{}
+	}
+
+	n := float64(len(b.documents))
+	averageLength := b.totalLength / n
+
+	scores := make(map[string]float64)
+	for i, term := range b.queryTerms(terms) {
+		for _, expanded := range term {
+			posting := b.postings[expanded]
+			idf := math.Log(1 + (n-float64(len(posting))+0.5)/(float64(len(posting))+0.5))
+
+			// Completions of the word being typed count less than the word itself
+			weight := 1.0
+			if expanded != terms[i] {
+				weight = 0.5
+			}
+
+			for id, frequency := range posting {
+				norm := bm25K1 * (1 - bm25B + bm25B*b.documents[id].length/averageLength)
+				scores[id] += weight * idf * frequency * (bm25K1 + 1) / (frequency + norm)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	if k > 0 && k < len(hits) {
+		hits = hits[:k]
+	}
+
+	if len(hits) > 0 {
+		best := hits[0].Score
+		for i := range hits {
+			hits[i].Score /= best
+		}
+	}
+
+	return hits
+}
+
+// queryTerms returns the indexed terms matched by each query term.
+// The last query term also matches the terms it is a prefix of.
+func (b *bm25Index) queryTerms(terms []string) [][]string {
+	expanded := make([][]string, len(terms))
+	for i, term := range terms {
+		expanded[i] = []string{term}
+	}
+
+	last := len(terms) - 1
+	if !expandsPrefix(terms[last]) {
+		return expanded
+	}
+
+	for indexed := range b.postings {
+		if indexed != terms[last] && strings.HasPrefix(indexed, terms[last]) {
+			expanded[last] = append(expanded[last], indexed)
+		}
+	}
+
+	return expanded
+}
+
+// expandsPrefix reports whether a query term is long enough to match the words it prefixes.
+// A single kanji or kana is a meaningful word, unlike a single letter.
+func expandsPrefix(term string) bool {
+	runes := []rune(term)
+	return len(runes) >= minPrefixLength || isCJK(runes[0])
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]struct{}, len(terms))
+	unique := terms[:0]
+	for _, term := range terms {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		unique = append(unique, term)
+	}
+
+	return unique
+}
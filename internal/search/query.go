@@ -0,0 +1,315 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"MMDContent/internal/entities"
+)
+
+// Fields are the attributes of a catalog item that queries filter on
+type Fields struct {
+	ID          string
+	Type        entities.ContentType
+	Name        string
+	Description string
+	Path        string
+	Screenshots int
+	Videos      int
+}
+
+// Filter is a node of the filter tree of a query
+type Filter interface {
+	Match(fields Fields) bool
+}
+
+// Query is a parsed search query. Free text ranks search results while filters
+// restrict which items can be returned, e.g.
+//
+//	type:motion has:video tag:dance path:"D:\MMD\Stages" -winter "exact phrase"
+//
+// Supported filters are type:, has: (video, screenshots, description, path),
+// tag: (a whole word of the name, path or description), path: (part of the
+// original path) and "quoted phrases". Any of them, and plain words, can be
+// negated with a leading "-".
+type Query struct {
+	// Filter holds every constraint of the query except the free text, nil when there are none
+	Filter Filter
+	// Terms are the free text words, in order
+	Terms []string
+	// Phrases are the quoted phrases, they are part of the filter and of the ranking text
+	Phrases []string
+}
+
+// ParseQuery parses the query syntax. Words that look like a filter but use an
+// unknown key (e.g. "Re:Zero") are kept as free text.
+func ParseQuery(input string) (Query, error) {
+	var q Query
+	var filters And
+
+	for _, part := range splitQuery(input) {
+		var filter Filter
+		key, value, isFilter := cutFilter(part)
+		switch {
+		case part.phrase:
+			filter = Contains{Terms: Tokenize(part.text)}
+			if !part.negated {
+				q.Phrases = append(q.Phrases, part.text)
+			}
+		case isFilter:
+			f, err := newFilter(key, value)
+			if err != nil {
+				return Query{}, err
+			}
+			filter = f
+		case part.negated:
+			filter = Contains{Terms: Tokenize(part.text)}
+		default:
+			q.Terms = append(q.Terms, part.text)
+			continue
+		}
+
+		if part.negated {
+			filter = Not{Filter: filter}
+		}
+		filters = append(filters, filter)
+	}
+
+	if len(filters) > 0 {
+		q.Filter = filters
+	}
+
+	return q, nil
+}
+
+// Text returns the free text and phrases of the query, used to rank results
+func (q Query) Text() string {
+	return strings.Join(append(append([]string{}, q.Terms...), q.Phrases...), " ")
+}
+
+// IsEmpty reports whether the query has neither free text nor filters
+func (q Query) IsEmpty() bool {
+	return q.Filter == nil && strings.TrimSpace(q.Text()) == ""
+}
+
+// Match reports whether an item passes the filters. Search ranks items by the
+// free text, so it does not have to appear in them.
+func (q Query) Match(fields Fields) bool {
+	return q.Filter == nil || q.Filter.Match(fields)
+}
+
+// MatchAll reports whether an item passes the filters and contains every word of
+// the free text. Used where there is no ranking, e.g. pagination and export.
+func (q Query) MatchAll(fields Fields) bool {
+	if !q.Match(fields) {
+		return false
+	}
+
+	for _, term := range q.Terms {
+		if !(Contains{Terms: Tokenize(term)}).Match(fields) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// And matches items that match all of its filters
+type And []Filter
+
+func (a And) Match(fields Fields) bool {
+	for _, filter := range a {
+		if !filter.Match(fields) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Not matches items that do not match its filter
+type Not struct {
+	Filter Filter
+}
+
+func (n Not) Match(fields Fields) bool {
+	return !n.Filter.Match(fields)
+}
+
+// Type matches items of a content type
+type Type struct {
+	Type entities.ContentType
+}
+
+func (t Type) Match(fields Fields) bool {
+	return fields.Type == t.Type
+}
+
+// Has matches items having a non empty attribute
+type Has struct {
+	Attribute string
+}
+
+func (h Has) Match(fields Fields) bool {
+	switch h.Attribute {
+	case "video":
+		return fields.Videos > 0
+	case "screenshots":
+		return fields.Screenshots > 0
+	case "description":
+		return strings.TrimSpace(fields.Description) != ""
+	case "path":
+		return fields.Path != ""
+	default:
+		return false
+	}
+}
+
+// Path matches items whose original path contains a value, ignoring case and the kind of slashes
+type Path struct {
+	Value string
+}
+
+func (p Path) Match(fields Fields) bool {
+	return strings.Contains(normalizePath(fields.Path), normalizePath(p.Value))
+}
+
+// Contains matches items whose name, path or description contains the terms next to each other
+type Contains struct {
+	Terms []string
+}
+
+func (c Contains) Match(fields Fields) bool {
+	if len(c.Terms) == 0 {
+		return true
+	}
+
+	for _, text := range []string{fields.Name, fields.Path, fields.Description} {
+		if containsSequence(Tokenize(text), c.Terms) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newFilter(key, value string) (Filter, error) {
+	switch key {
+	case "type":
+		contentType := entities.ContentType(strings.ToLower(strings.TrimSuffix(value, "s")))
+		switch contentType {
+		case entities.ContentTypeModel, entities.ContentTypeStage, entities.ContentTypeMotion:
+			return Type{Type: contentType}, nil
+		}
+		return nil, fmt.Errorf("unknown content type %q in type:", value)
+	case "has":
+		attribute := strings.ToLower(value)
+		switch attribute {
+		case "video", "videos":
+			return Has{Attribute: "video"}, nil
+		case "screenshots", "screenshot", "images", "image":
+			return Has{Attribute: "screenshots"}, nil
+		case "description", "path":
+			return Has{Attribute: attribute}, nil
+		}
+		return nil, fmt.Errorf("unknown attribute %q in has:", value)
+	case "tag":
+		return Contains{Terms: Tokenize(value)}, nil
+	case "path":
+		return Path{Value: value}, nil
+	default:
+		return nil, fmt.Errorf("unknown filter %q", key)
+	}
+}
+
+// queryPart is a whitespace separated part of a query, or a quoted phrase
+type queryPart struct {
+	text    string
+	phrase  bool
+	negated bool
+}
+
+// splitQuery splits a query on whitespace, keeping quoted text together, e.g. a
+// phrase or a filter value like path:"D:\MMD\My Stages". Backslashes are not
+// escapes so that Windows paths can be pasted as they are.
+func splitQuery(input string) []queryPart {
+	var parts []queryPart
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		var part queryPart
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			part.negated = true
+			i++
+		}
+		part.phrase = runes[i] == '"'
+
+		var text strings.Builder
+		inQuotes := false
+		for ; i < len(runes) && (inQuotes || !unicode.IsSpace(runes[i])); i++ {
+			if runes[i] == '"' {
+				inQuotes = !inQuotes
+				if !inQuotes && part.phrase {
+					i++
+					break
+				}
+				continue
+			}
+			text.WriteRune(runes[i])
+		}
+
+		part.text = text.String()
+		if strings.TrimSpace(part.text) != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return parts
+}
+
+// cutFilter splits key:value, filters have a known key and a value
+func cutFilter(part queryPart) (key, value string, ok bool) {
+	if part.phrase {
+		return "", "", false
+	}
+
+	key, value, found := strings.Cut(part.text, ":")
+	if !found || value == "" {
+		return "", "", false
+	}
+
+	key = strings.ToLower(key)
+	switch key {
+	case "type", "has", "tag", "path":
+		return key, value, true
+	default:
+		return "", "", false
+	}
+}
+
+func normalizePath(path string) string {
+	return strings.ToLower(strings.ReplaceAll(path, `\`, "/"))
+}
+
+func containsSequence(tokens, sequence []string) bool {
+	for i := 0; i+len(sequence) <= len(tokens); i++ {
+		match := true
+		for j, term := range sequence {
+			if tokens[i+j] != term {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
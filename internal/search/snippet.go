@@ -0,0 +1,133 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"MMDContent/internal/entities"
+)
+
+const (
+	// snippetContext is the number of bytes of text kept around a match
+	snippetContext = 60
+	maxSnippets    = 3
+)
+
+// Snippets returns the passages of text containing words of the query, with the
+// matching words highlighted, or nil when none of them appears in text
+func Snippets(text, query string) []entities.Snippet {
+	matcher := newQueryMatcher(uniqueTerms(Tokenize(query)))
+	if matcher.empty() {
+		return nil
+	}
+
+	// Byte ranges of the matching words, overlapping ranges merged
+	var matches [][2]int
+	for _, t := range tokenize(text) {
+		if !matcher.match(t.term) {
+			continue
+		}
+		if last := len(matches) - 1; last >= 0 && t.start <= matches[last][1] {
+			matches[last][1] = max(matches[last][1], t.end)
+			continue
+		}
+		matches = append(matches, [2]int{t.start, t.end})
+	}
+
+	var snippets []entities.Snippet
+	for i := 0; i < len(matches) && len(snippets) < maxSnippets; {
+		start := snippetStart(text, matches[i][0])
+
+		// Take every match whose context overlaps the one of the previous match
+		j := i + 1
+		for j < len(matches) && matches[j][0]-matches[j-1][1] <= 2*snippetContext {
+			j++
+		}
+		end := snippetEnd(text, matches[j-1][1])
+
+		var parts []entities.SnippetPart
+		if start > 0 {
+			parts = append(parts, entities.SnippetPart{Text: "…"})
+		}
+		position := start
+		for _, match := range matches[i:j] {
+			if match[0] > position {
+				parts = append(parts, entities.SnippetPart{Text: text[position:match[0]]})
+			}
+			parts = append(parts, entities.SnippetPart{Text: text[match[0]:match[1]], Match: true})
+			position = match[1]
+		}
+		if end > position {
+			parts = append(parts, entities.SnippetPart{Text: text[position:end]})
+		}
+		if end < len(text) {
+			parts = append(parts, entities.SnippetPart{Text: "…"})
+		}
+
+		snippets = append(snippets, entities.Snippet{Parts: parts})
+		i = j
+	}
+
+	return snippets
+}
+
+// snippetStart returns where the context before a match starts, on a word boundary if possible
+func snippetStart(text string, match int) int {
+	start := max(match-snippetContext, 0)
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	if start > 0 {
+		if space := strings.IndexAny(text[start:match], " \n\t"); space >= 0 {
+			start += space + 1
+		}
+	}
+
+	return start
+}
+
+// snippetEnd returns where the context after a match ends, on a word boundary if possible
+func snippetEnd(text string, match int) int {
+	end := min(match+snippetContext, len(text))
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	if end < len(text) {
+		if space := strings.LastIndexAny(text[match:end], " \n\t"); space >= 0 {
+			end = match + space
+		}
+	}
+
+	return end
+}
+
+// queryMatcher tells whether a term of a text matches the terms of a query.
+// Like the keyword index, the last query term also matches the words it prefixes.
+type queryMatcher struct {
+	terms  map[string]struct{}
+	prefix string
+}
+
+func newQueryMatcher(terms []string) queryMatcher {
+	m := queryMatcher{terms: make(map[string]struct{}, len(terms))}
+	for _, term := range terms {
+		m.terms[term] = struct{}{}
+	}
+	if len(terms) > 0 && expandsPrefix(terms[len(terms)-1]) {
+		m.prefix = terms[len(terms)-1]
+	}
+
+	return m
+}
+
+func (m queryMatcher) empty() bool {
+	return len(m.terms) == 0
+}
+
+func (m queryMatcher) match(term string) bool {
+	if _, ok := m.terms[term]; ok {
+		return true
+	}
+
+	return m.prefix != "" && strings.HasPrefix(term, m.prefix)
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// token is a search term and its byte range in the original text
+type token struct {
+	term       string
+	start, end int
+}
+
+// Tokenize splits text into lowercase search terms. Accents are removed so that
+// "canción" matches "cancion", and Chinese/Japanese text, which has no spaces,
+// is split into overlapping character bigrams.
+func Tokenize(text string) []string {
+	tokens := tokenize(text)
+	terms := make([]string, len(tokens))
+	for i, t := range tokens {
+		terms[i] = t.term
+	}
+
+	return terms
+}
+
+func tokenize(text string) []token {
+	var tokens []token
+	var word []rune
+	wordStart := 0
+
+	type cjkRune struct {
+		r          rune
+		start, end int
+	}
+	var cjk []cjkRune
+
+	flushWord := func(end int) {
+		if len(word) > 0 {
+			tokens = append(tokens, token{term: string(word), start: wordStart, end: end})
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch len(cjk) {
+		case 0:
+		case 1:
+			// A lone character is kept as is
+			tokens = append(tokens, token{term: string(cjk[0].r), start: cjk[0].start, end: cjk[0].end})
+		default:
+			for i := 0; i+1 < len(cjk); i++ {
+				term := string([]rune{cjk[i].r, cjk[i+1].r})
+				tokens = append(tokens, token{term: term, start: cjk[i].start, end: cjk[i+1].end})
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for start, original := range text {
+		end := start + len(string(original))
+
+		// Decompose each character on its own so that tokens keep their position,
+		// e.g. "é" becomes "e" and a combining accent, fullwidth "Ｍ" becomes "M"
+		for _, r := range norm.NFKD.String(string(original)) {
+			switch {
+			case unicode.Is(unicode.Mn, r):
+				// Combining mark left by the decomposition of an accented letter
+				continue
+			case isCJK(r):
+				flushWord(start)
+				cjk = append(cjk, cjkRune{r: r, start: start, end: end})
+			case unicode.IsLetter(r) || unicode.IsNumber(r):
+				flushCJK()
+				if len(word) == 0 {
+					wordStart = start
+				}
+				word = append(word, unicode.ToLower(r))
+			default:
+				flushWord(start)
+				flushCJK()
+			}
+		}
+	}
+	flushWord(len(text))
+	flushCJK()
+
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == 'ー'
+}
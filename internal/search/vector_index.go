@@ -0,0 +1,543 @@
+package search
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"MMDContent/internal/entities"
+	"MMDContent/internal/quantize"
+	"MMDContent/internal/storage"
+)
+
+// VectorIndex keeps one HNSW graph per content type in sync with the vector store.
+// Graphs are persisted to dir and reconciled with the store on load, so a launch
+// only indexes the vectors that changed since the last save.
+type VectorIndex struct {
+	mu      sync.RWMutex
+	dir     string
+	vectors *storage.Vectors
+	accept  func(entities.EmbeddingInfo) bool
+	weights FieldWeights
+	graphs  map[entities.ContentType]*HNSW
+}
+
+// FieldWeights sets how much the similarity of each embedded field counts in the
+// score of an item
+type FieldWeights struct {
+	Name        float64
+	Description float64
+	Path        float64
+}
+
+// DefaultFieldWeights favors the name and description over the folders of an item
+var DefaultFieldWeights = FieldWeights{Name: 1, Description: 1, Path: 0.5}
+
+func (w FieldWeights) weight(field string) float64 {
+	switch field {
+	case entities.EmbeddingFieldName:
+		return w.Name
+	case entities.EmbeddingFieldDescription:
+		return w.Description
+	case entities.EmbeddingFieldPath:
+		return w.Path
+	default:
+		// Name and description embedded together
+		return 1
+	}
+}
+
+// NewVectorIndexLoaded loads the graphs of the given content types. Only vectors for
+// which accept returns true are indexed, e.g. those of the current embedding model.
+func NewVectorIndexLoaded(
+	dir string,
+	vectors *storage.Vectors,
+	accept func(entities.EmbeddingInfo) bool,
+	weights FieldWeights,
+	contentTypes ...entities.ContentType,
+) (*VectorIndex, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &VectorIndex{
+		dir:     dir,
+		vectors: vectors,
+		accept:  accept,
+		weights: weights,
+		graphs:  make(map[entities.ContentType]*HNSW, len(contentTypes)),
+	}
+
+	for _, contentType := range contentTypes {
+		graph, err := loadHNSW(x.filename(contentType), vectors.Encoding())
+		if err != nil {
+			slog.Warn("rebuilding vector index", "type", contentType, "error", err)
+			graph = NewHNSW(x.vectors.Encoding())
+		}
+
+		x.graphs[contentType] = graph
+		x.reconcile(contentType)
+	}
+
+	vectors.Subscribe(x.apply)
+
+	return x, nil
+}
+
+// Search returns the k items of a content type most similar to the query, best first.
+// Candidates found in the graph are scored by the weighted similarity of each of their
+// fields, a field embedded in several chunks counting its best one. The position of the
+// best passage of the description is set on the hit.
+func (x *VectorIndex) Search(contentType entities.ContentType, query []float64, k int) []Hit {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	graph, ok := x.graphs[contentType]
+	if !ok {
+		return []Hit{}
+	}
+
+	// Weights reorder the graph ranking, score more candidates than needed
+	n := k
+	if k > 0 {
+		n = max(2*k, minFusionCandidates)
+	}
+
+	// Several chunks of an item can come back, fetch enough of them to find n items
+	fetch := n
+	if n > 0 {
+		items := max(1, x.vectors.Count(contentType))
+		fetch = n * max(1, (graph.Len()+items-1)/items)
+	}
+
+	var candidates []Hit
+	for {
+		candidates = bestChunks(graph.Search(query, fetch))
+		if n <= 0 || len(candidates) >= n || fetch >= graph.Len() {
+			break
+		}
+		fetch *= 2
+	}
+
+	q := make([]float32, len(query))
+	for i, value := range query {
+		q[i] = float32(value)
+	}
+	q = normalize(q)
+
+	hits := make([]Hit, 0, len(candidates))
+	for _, candidate := range candidates {
+		id, _ := parseChunkLabel(candidate.ID)
+		vector, ok := x.vectors.Get(contentType, id)
+		if !ok {
+			continue
+		}
+
+		hit := x.score(vector, q)
+		hit.ID = id
+		hits = append(hits, hit)
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	if k > 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+
+	return hits
+}
+
+// Similar returns the k items of targetType most similar to the stored vector of an
+// item of sourceType, best first and without the item itself. It returns false when the
+// item has no vector of the accepted embedding model. The item is compared by the mean
+// of its fields, weighted like in a search.
+func (x *VectorIndex) Similar(
+	sourceType entities.ContentType,
+	id string,
+	targetType entities.ContentType,
+	k int,
+) ([]Hit, bool) {
+	vector, ok := x.vectors.Get(sourceType, id)
+	if !ok || !x.accept(vector.Info) || len(vector.Chunks) == 0 {
+		return nil, false
+	}
+
+	query := make([]float64, vector.Info.Dimensions)
+	for field, chunks := range fieldChunks(vector) {
+		mean := make([]float32, vector.Info.Dimensions)
+		for _, chunk := range chunks {
+			for i := range min(chunk.Values.Len(), len(mean)) {
+				mean[i] += chunk.Values.At(i) / float32(len(chunks))
+			}
+		}
+
+		weight := x.weights.weight(field)
+		for i, value := range normalize(mean) {
+			query[i] += weight * float64(value)
+		}
+	}
+
+	// The item finds itself first when it is of the target type
+	n := k
+	if k > 0 && sourceType == targetType {
+		n = k + 1
+	}
+
+	hits := x.Search(targetType, query, n)
+	similar := make([]Hit, 0, len(hits))
+	for _, hit := range hits {
+		if sourceType == targetType && hit.ID == id {
+			continue
+		}
+		similar = append(similar, hit)
+	}
+
+	if k > 0 && len(similar) > k {
+		similar = similar[:k]
+	}
+
+	return similar, true
+}
+
+// score computes the similarity of an item to a normalized query. Fields the item does
+// not have (e.g. an empty description) are left out rather than counted as a mismatch.
+func (x *VectorIndex) score(vector storage.Vector, q []float32) Hit {
+	var hit Hit
+	var total, weights float64
+	passage := -1.0
+
+	for field, chunks := range fieldChunks(vector) {
+		best, scored := -1.0, false
+		for _, chunk := range chunks {
+			if chunk.Values.Len() != len(q) {
+				continue
+			}
+			norm := math.Sqrt(chunk.Values.DotVector(chunk.Values))
+			if norm == 0 {
+				continue
+			}
+
+			similarity := chunk.Values.Dot(q) / norm
+			best, scored = max(best, similarity), true
+
+			// Point at the best passage of the description
+			if chunk.End > chunk.Start && similarity > passage {
+				passage = similarity
+				hit.Start, hit.End = chunk.Start, chunk.End
+			}
+		}
+		if !scored {
+			continue
+		}
+
+		weight := x.weights.weight(field)
+		total += weight * best
+		weights += weight
+	}
+
+	if weights > 0 {
+		hit.Score = total / weights
+	}
+
+	return hit
+}
+
+// Save persists every graph
+func (x *VectorIndex) Save() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for contentType, graph := range x.graphs {
+		if graph.Fragmented() {
+			x.graphs[contentType] = rebuildHNSW(graph, x.vectors, contentType)
+			graph = x.graphs[contentType]
+		}
+
+		if err := saveHNSW(x.filename(contentType), graph); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply mirrors vector store changes into the graphs
+func (x *VectorIndex) apply(changes []storage.VectorChange) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, change := range changes {
+		graph, ok := x.graphs[change.ContentType]
+		if !ok {
+			continue
+		}
+
+		// Only deleted nodes left, start afresh so vectors of another size can be indexed
+		if graph.Len() == 0 && len(graph.Nodes) > 0 {
+			graph = NewHNSW(x.vectors.Encoding())
+			x.graphs[change.ContentType] = graph
+		}
+
+		// The new vector may have fewer chunks, drop them all first
+		deleteItem(graph, change.ID)
+		if change.Vector == nil || !x.accept(change.Vector.Info) {
+			continue
+		}
+
+		// A vector of another size cannot join the graph, the accepted vectors changed
+		// size (e.g. with the model), index the ones of the new size instead
+		if dimensions := change.Vector.Info.Dimensions; graph.Dimensions != 0 && dimensions != graph.Dimensions {
+			slog.Warn("vector size changed, rebuilding vector index", "type", change.ContentType,
+				"from", graph.Dimensions, "to", dimensions)
+			graph = x.build(change.ContentType, dimensions)
+			x.graphs[change.ContentType] = graph
+			continue
+		}
+
+		insertItem(graph, change.ID, *change.Vector)
+	}
+}
+
+// build indexes every accepted vector of a content type of the given size. A graph holds
+// vectors of one size, the others are left out.
+func (x *VectorIndex) build(contentType entities.ContentType, dimensions int) *HNSW {
+	graph := NewHNSW(x.vectors.Encoding())
+	graph.Dimensions = dimensions
+
+	skipped := 0
+	x.vectors.Range(contentType, func(id string, vector storage.Vector) {
+		if !x.accept(vector.Info) {
+			return
+		}
+		if vector.Info.Dimensions != dimensions {
+			skipped++
+			return
+		}
+		insertItem(graph, id, vector)
+	})
+
+	if skipped > 0 {
+		slog.Warn("vectors of another size left out of the vector index", "type", contentType,
+			"dimensions", dimensions, "skipped", skipped)
+	}
+
+	return graph
+}
+
+// reconcile brings a loaded graph up to date with the vector store
+func (x *VectorIndex) reconcile(contentType entities.ContentType) {
+	graph := x.graphs[contentType]
+
+	stored := make(map[string]storage.Vector)
+	x.vectors.Range(contentType, func(id string, vector storage.Vector) {
+		if x.accept(vector.Info) {
+			stored[id] = vector
+		}
+	})
+
+	// A graph of another size cannot take the stored vectors, e.g. after switching model
+	dimensions := commonDimensions(stored)
+	if graph.Dimensions != 0 && dimensions != 0 && graph.Dimensions != dimensions {
+		x.graphs[contentType] = x.build(contentType, dimensions)
+		return
+	}
+
+	indexed := make(map[string]int, len(stored))
+	for _, label := range graph.Labels() {
+		id, n := parseChunkLabel(label)
+		vector, ok := stored[id]
+		key, _ := graph.Key(label)
+		if !ok || n >= len(vector.Chunks) || key != vectorKey(vector.Info) {
+			graph.Delete(label)
+			continue
+		}
+		indexed[id]++
+	}
+
+	for id, vector := range stored {
+		if indexed[id] == len(vector.Chunks) {
+			delete(stored, id)
+		}
+	}
+
+	// A graph of another embedding model (e.g. after switching provider) is useless
+	if graph.Len() == 0 {
+		x.graphs[contentType] = x.build(contentType, dimensions)
+		return
+	}
+	if graph.Fragmented() {
+		graph = rebuildHNSW(graph, x.vectors, contentType)
+	}
+
+	// Whatever is left is new or was re-embedded
+	skipped := 0
+	for id, vector := range stored {
+		if vector.Info.Dimensions != graph.Dimensions {
+			skipped++
+			continue
+		}
+		insertItem(graph, id, vector)
+	}
+	if skipped > 0 {
+		slog.Warn("vectors of another size left out of the vector index", "type", contentType,
+			"dimensions", graph.Dimensions, "skipped", skipped)
+	}
+
+	x.graphs[contentType] = graph
+}
+
+// commonDimensions returns the most frequent size of the vectors, the largest on a tie,
+// 0 when there are none
+func commonDimensions(vectors map[string]storage.Vector) int {
+	counts := make(map[int]int)
+	common := 0
+	for _, vector := range vectors {
+		dimensions := vector.Info.Dimensions
+		counts[dimensions]++
+		if counts[dimensions] > counts[common] || counts[dimensions] == counts[common] && dimensions > common {
+			common = dimensions
+		}
+	}
+
+	return common
+}
+
+func (x *VectorIndex) filename(contentType entities.ContentType) string {
+	return filepath.Join(x.dir, string(contentType)+".hnsw")
+}
+
+// vectorKey identifies the version of a vector: it changes whenever the item is re-embedded
+func vectorKey(info entities.EmbeddingInfo) string {
+	return fmt.Sprintf("%s|%s|%d|%s", info.Provider, info.Model, info.Dimensions, info.TextHash)
+}
+
+// rebuildHNSW indexes the live items of graph again, dropping deleted nodes
+func rebuildHNSW(graph *HNSW, vectors *storage.Vectors, contentType entities.ContentType) *HNSW {
+	rebuilt := NewHNSW(vectors.Encoding())
+	for _, label := range graph.Labels() {
+		id, n := parseChunkLabel(label)
+		vector, ok := vectors.Get(contentType, id)
+		if !ok {
+			continue
+		}
+		if chunk, ok := vector.Chunk(n); ok {
+			rebuilt.Insert(label, vectorKey(vector.Info), chunk.Values.Floats())
+		}
+	}
+
+	return rebuilt
+}
+
+// Each chunk of an item is a node of the graph. The first chunk is labelled with the
+// item ID, so that graphs saved before chunking stay valid, the next ones with the
+// ID and the chunk number.
+func chunkLabel(id string, n int) string {
+	if n == 0 {
+		return id
+	}
+
+	return id + "\x00" + strconv.Itoa(n)
+}
+
+func parseChunkLabel(label string) (string, int) {
+	id, number, found := strings.Cut(label, "\x00")
+	if !found {
+		return label, 0
+	}
+
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return id, 0
+	}
+
+	return id, n
+}
+
+func insertItem(graph *HNSW, id string, vector storage.Vector) {
+	for n, chunk := range vector.Chunks {
+		graph.Insert(chunkLabel(id, n), vectorKey(vector.Info), chunk.Values.Floats())
+	}
+}
+
+func deleteItem(graph *HNSW, id string) {
+	for n := 0; ; n++ {
+		label := chunkLabel(id, n)
+		if _, ok := graph.Key(label); !ok {
+			return
+		}
+		graph.Delete(label)
+	}
+}
+
+// fieldChunks groups the chunks of a vector by field
+func fieldChunks(vector storage.Vector) map[string][]storage.VectorChunk {
+	fields := make(map[string][]storage.VectorChunk)
+	for _, chunk := range vector.Chunks {
+		fields[chunk.Field] = append(fields[chunk.Field], chunk)
+	}
+
+	return fields
+}
+
+// bestChunks keeps the best scoring chunk of every item, hits must be sorted best first
+func bestChunks(hits []Hit) []Hit {
+	seen := make(map[string]bool, len(hits))
+	best := make([]Hit, 0, len(hits))
+	for _, hit := range hits {
+		id, _ := parseChunkLabel(hit.ID)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		best = append(best, hit)
+	}
+
+	return best
+}
+
+// loadHNSW loads a saved graph, it must use the encoding of the vector store
+func loadHNSW(filename string, encoding quantize.Encoding) (*HNSW, error) {
+	file, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewHNSW(encoding), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var graph HNSW
+	if err := gob.NewDecoder(file).Decode(&graph); err != nil {
+		return nil, err
+	}
+	if graph.Encoding != encoding {
+		return nil, fmt.Errorf("graph is encoded as %s instead of %s", graph.Encoding, encoding)
+	}
+	graph.init()
+
+	return &graph, nil
+}
+
+func saveHNSW(filename string, graph *HNSW) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(graph); err != nil {
+		return err
+	}
+
+	return storage.WriteFileAtomic(filename, buf.Bytes())
+}
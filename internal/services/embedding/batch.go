@@ -0,0 +1,64 @@
+package embedding
+
+import (
+	"unicode/utf8"
+)
+
+const (
+	// DefaultBatchTokens keeps requests well under the 300k tokens OpenAI accepts per call
+	DefaultBatchTokens = 100_000
+	// DefaultBatchInputs is below the 2048 inputs OpenAI accepts per call
+	DefaultBatchInputs = 512
+)
+
+// Batch is the half-open range [Start, End) of texts sent in one request
+type Batch struct {
+	Start int
+	End   int
+}
+
+// EstimateTokens approximates the number of tokens of text without a tokenizer:
+// roughly four bytes per token for latin text and one token per CJK character
+func EstimateTokens(text string) int {
+	tokens := 0
+	latinBytes := 0
+	for _, r := range text {
+		if isCJK(r) {
+			tokens++
+			continue
+		}
+		latinBytes += utf8.RuneLen(r)
+	}
+
+	return tokens + (latinBytes+3)/4
+}
+
+// SplitBatches groups consecutive texts so that no batch exceeds maxTokens estimated
+// tokens or maxInputs texts. A single text above maxTokens gets a batch of its own.
+func SplitBatches(texts []string, maxTokens, maxInputs int) []Batch {
+	if maxTokens <= 0 {
+		maxTokens = DefaultBatchTokens
+	}
+	if maxInputs <= 0 {
+		maxInputs = DefaultBatchInputs
+	}
+
+	var batches []Batch
+	start := 0
+	tokens := 0
+	for i, text := range texts {
+		estimated := EstimateTokens(text)
+		if i > start && (tokens+estimated > maxTokens || i-start >= maxInputs) {
+			batches = append(batches, Batch{Start: start, End: i})
+			start = i
+			tokens = 0
+		}
+		tokens += estimated
+	}
+
+	if start < len(texts) {
+		batches = append(batches, Batch{Start: start, End: len(texts)})
+	}
+
+	return batches
+}
@@ -0,0 +1,51 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Cache stores embeddings by key
+type Cache interface {
+	Get(key string) ([]float64, bool)
+	Put(key string, values []float64)
+}
+
+// Cached is a provider that remembers the embeddings of single texts, e.g. search
+// queries, so that typing the same query again needs no API call. Batches used to
+// embed the catalog are not cached.
+type Cached struct {
+	Provider
+	cache Cache
+}
+
+func NewCached(provider Provider, cache Cache) *Cached {
+	return &Cached{
+		Provider: provider,
+		cache:    cache,
+	}
+}
+
+// GenerateEmbedding returns the cached embedding of the text, generating it on a miss
+func (c *Cached) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	key := c.key(text)
+	if values, ok := c.cache.Get(key); ok {
+		return values, nil
+	}
+
+	values, err := c.Provider.GenerateEmbedding(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.Put(key, values)
+
+	return values, nil
+}
+
+// key identifies the embedding of a text by provider, model, size and normalized text,
+// so that "Cherry  Blossoms" and "cherry blossoms" share an entry
+func (c *Cached) key(text string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	return fmt.Sprintf("%s|%s|%d|%s", c.Name(), c.Model(), c.Dimensions(), normalized)
+}
@@ -0,0 +1,93 @@
+package embedding
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultChunkTokens keeps passages small enough to have a focused meaning
+	DefaultChunkTokens = 512
+	// DefaultChunkOverlap is repeated between consecutive passages so that a
+	// sentence cut at the end of one is whole at the start of the next
+	DefaultChunkOverlap = 64
+)
+
+// Chunk is the byte range [Start, End) of a passage of a text
+type Chunk struct {
+	Start int
+	End   int
+}
+
+// SplitChunks splits text into passages of at most maxTokens estimated tokens,
+// consecutive passages sharing about overlap tokens. Passages are cut on whitespace
+// when possible. A text that fits is returned as a single chunk.
+func SplitChunks(text string, maxTokens, overlap int) []Chunk {
+	if maxTokens <= 0 {
+		maxTokens = DefaultChunkTokens
+	}
+	overlap = max(0, min(overlap, maxTokens/2))
+
+	// Costs are counted in quarters of a token, see EstimateTokens
+	offsets := make([]int, 0, len(text)+1)
+	costs := make([]int, 0, len(text)+1)
+	cost := 0
+	for offset, r := range text {
+		offsets = append(offsets, offset)
+		costs = append(costs, cost)
+		if isCJK(r) {
+			cost += 4
+		} else {
+			cost += utf8.RuneLen(r)
+		}
+	}
+	offsets = append(offsets, len(text))
+	costs = append(costs, cost)
+
+	if cost <= maxTokens*4 {
+		return []Chunk{{Start: 0, End: len(text)}}
+	}
+
+	runes := []rune(text)
+	var chunks []Chunk
+	start := 0
+	for start < len(runes) {
+		// Furthest end within budget
+		end := start
+		for end < len(runes) && costs[end+1]-costs[start] <= maxTokens*4 {
+			end++
+		}
+		end = max(end, start+1)
+
+		// Prefer to cut after a space in the second half of the passage
+		if end < len(runes) {
+			for cut := end; cut > start+(end-start)/2; cut-- {
+				if unicode.IsSpace(runes[cut-1]) {
+					end = cut
+					break
+				}
+			}
+		}
+
+		chunks = append(chunks, Chunk{Start: offsets[start], End: offsets[end]})
+		if end == len(runes) {
+			break
+		}
+
+		// Step back by the overlap, to the start of a word, always moving forward
+		next := end
+		for next > start+1 && costs[end]-costs[next-1] <= overlap*4 {
+			next--
+		}
+		for next < end && next > start+1 && !unicode.IsSpace(runes[next-1]) {
+			next++
+		}
+		start = next
+	}
+
+	return chunks
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
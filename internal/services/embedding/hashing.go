@@ -0,0 +1,103 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const defaultHashingDimensions = 512
+
+// Hashing is a deterministic offline embedder based on feature hashing.
+// It needs no network access, so search keeps working without any API,
+// at the cost of only capturing lexical (not semantic) similarity.
+type Hashing struct {
+	dimensions int
+}
+
+func NewHashing(dimensions int) *Hashing {
+	if dimensions <= 0 {
+		dimensions = defaultHashingDimensions
+	}
+
+	return &Hashing{dimensions: dimensions}
+}
+
+func (h *Hashing) Name() string {
+	return ProviderOffline
+}
+
+func (h *Hashing) Model() string {
+	return fmt.Sprintf("hashing-%d", h.dimensions)
+}
+
+func (h *Hashing) Dimensions() int {
+	return h.dimensions
+}
+
+// GenerateEmbedding hashes words and character trigrams into a fixed size, normalized vector
+func (h *Hashing) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vector := make([]float64, h.dimensions)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, word := range words {
+		h.add(vector, "w:"+word, 1)
+
+		runes := []rune("^" + word + "$")
+		for i := 0; i+3 <= len(runes); i++ {
+			h.add(vector, "t:"+string(runes[i:i+3]), 0.5)
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] /= norm
+		}
+	}
+
+	return vector, nil
+}
+
+// GenerateEmbeddings hashes every text, see GenerateEmbedding
+func (h *Hashing) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	embeddings := make([][]float64, len(texts))
+	for i, text := range texts {
+		embedding, err := h.GenerateEmbedding(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		embeddings[i] = embedding
+	}
+
+	return embeddings, nil
+}
+
+// add accumulates a feature into its hashed bucket, using a second hash bit as the sign
+// so that collisions tend to cancel out instead of piling up
+func (h *Hashing) add(vector []float64, feature string, weight float64) {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(feature))
+	sum := hasher.Sum64()
+
+	index := int(sum % uint64(h.dimensions))
+	if (sum>>63)&1 == 1 {
+		weight = -weight
+	}
+
+	vector[index] += weight
+}
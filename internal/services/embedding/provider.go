@@ -0,0 +1,117 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"MMDContent/internal/entities"
+	"MMDContent/internal/services/ollama"
+	"MMDContent/internal/services/openai"
+)
+
+const (
+	ProviderOpenAI  = "openai"
+	ProviderOllama  = "ollama"
+	ProviderOffline = "offline"
+	ProviderNone    = "none"
+)
+
+// Provider turns text into vector embeddings
+type Provider interface {
+	// Name returns the backend identifier (openai, ollama or offline)
+	Name() string
+	// Model returns the embedding model used by the backend
+	Model() string
+	// Dimensions returns the requested embedding size, 0 when the model default is used
+	Dimensions() int
+	// GenerateEmbedding creates a vector embedding for the given text,
+	// giving up as soon as the context is cancelled
+	GenerateEmbedding(ctx context.Context, text string) ([]float64, error)
+	// GenerateEmbeddings creates one embedding per text, in the same order
+	GenerateEmbeddings(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// SameSpace reports whether an embedding can be compared with vectors of the provider.
+// Vectors of different models or sizes are not comparable.
+func SameSpace(info entities.EmbeddingInfo, provider Provider) bool {
+	if dimensions := provider.Dimensions(); dimensions > 0 && info.Dimensions != dimensions {
+		return false
+	}
+
+	// Legacy embeddings have no fingerprint, they all came from OpenAI text-embedding-3-large
+	if info.Provider == "" {
+		return provider.Name() == ProviderOpenAI && provider.Model() == openai.DefaultModel
+	}
+
+	return info.Provider == provider.Name() && info.Model == provider.Model()
+}
+
+// IsInputRejected reports whether the backend refused a request because of the texts sent,
+// e.g. one that is empty or too long. Splitting the batch then isolates the texts at fault.
+func IsInputRejected(err error) bool {
+	status := 0
+	var openaiErr *openai.APIError
+	var ollamaErr *ollama.APIError
+	switch {
+	case errors.As(err, &openaiErr):
+		status = openaiErr.StatusCode
+	case errors.As(err, &ollamaErr):
+		status = ollamaErr.StatusCode
+	}
+
+	return status == http.StatusBadRequest ||
+		status == http.StatusRequestEntityTooLarge ||
+		status == http.StatusUnprocessableEntity
+}
+
+type Config struct {
+	Provider   string
+	BaseURL    string
+	Model      string
+	APIKey     string
+	Dimensions int
+	Timeout    time.Duration
+	MaxRetries int
+	// OnUsage receives the tokens billed by providers reporting them (openai), it may be nil
+	OnUsage openai.UsageFunc
+}
+
+// NewProvider builds the provider selected by the configuration, defaulting to OpenAI.
+// It returns a nil provider when none is configured: no provider is selected and
+// there is no API key or base URL, or the provider is "none". Search then only uses keywords.
+func NewProvider(cfg Config) (Provider, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Provider)) {
+	case "":
+		if cfg.APIKey == "" && cfg.BaseURL == "" {
+			return nil, nil
+		}
+		cfg.Provider = ProviderOpenAI
+		return NewProvider(cfg)
+	case ProviderNone:
+		return nil, nil
+	case ProviderOpenAI:
+		return openai.NewClient(openai.Config{
+			APIKey:     cfg.APIKey,
+			BaseURL:    cfg.BaseURL,
+			Model:      cfg.Model,
+			Dimensions: cfg.Dimensions,
+			Timeout:    cfg.Timeout,
+			MaxRetries: cfg.MaxRetries,
+			OnUsage:    cfg.OnUsage,
+		}), nil
+	case ProviderOllama:
+		return ollama.NewClient(ollama.Config{
+			BaseURL: cfg.BaseURL,
+			Model:   cfg.Model,
+			Timeout: cfg.Timeout,
+		}), nil
+	case ProviderOffline:
+		return NewHashing(0), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", cfg.Provider)
+	}
+}
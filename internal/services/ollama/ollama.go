@@ -0,0 +1,143 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL = "http://localhost:11434"
+	DefaultModel   = "nomic-embed-text"
+	DefaultTimeout = 60 * time.Second
+)
+
+// APIError is returned when the server answers with a non 200 status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Ollama API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+type Config struct {
+	BaseURL string
+	Model   string
+	// Timeout bounds every request, 0 uses DefaultTimeout
+	Timeout time.Duration
+}
+
+type Client struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+func NewClient(cfg Config) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = DefaultModel
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		model:      model,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type EmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+type EmbeddingResponse struct {
+	Embedding []float64 `json:"embedding"`
+}
+
+func (c *Client) Name() string {
+	return "ollama"
+}
+
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Dimensions returns 0, Ollama always answers with the model default size
+func (c *Client) Dimensions() int {
+	return 0
+}
+
+// GenerateEmbedding creates a vector embedding for the given text using a local Ollama server
+func (c *Client) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	requestBody := EmbeddingRequest{
+		Model:  c.model,
+		Prompt: text,
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/embeddings", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var embeddingResp EmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embeddingResp); err != nil {
+		return nil, err
+	}
+
+	if len(embeddingResp.Embedding) == 0 {
+		return nil, fmt.Errorf("no embedding returned from Ollama")
+	}
+
+	return embeddingResp.Embedding, nil
+}
+
+// GenerateEmbeddings creates embeddings for several texts. The /api/embeddings
+// endpoint takes a single prompt, so texts are sent one after another.
+func (c *Client) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	embeddings := make([][]float64, len(texts))
+	for i, text := range texts {
+		embedding, err := c.GenerateEmbedding(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		embeddings[i] = embedding
+	}
+
+	return embeddings, nil
+}
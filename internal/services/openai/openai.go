@@ -2,40 +2,176 @@ package openai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const (
+	DefaultBaseURL    = "https://api.openai.com/v1"
+	DefaultModel      = "text-embedding-3-large"
+	DefaultTimeout    = 30 * time.Second
+	DefaultMaxRetries = 3
+
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+	// maxRetryAfter is the longest Retry-After waited for, the request fails beyond it
+	maxRetryAfter = 2 * time.Minute
+)
+
+// PricesPerMillionTokens are the list prices in USD of the OpenAI embedding models
+var PricesPerMillionTokens = map[string]float64{
+	"text-embedding-3-small": 0.02,
+	"text-embedding-3-large": 0.13,
+	"text-embedding-ada-002": 0.10,
+}
+
+// UsageFunc receives the number of tokens billed for a successful request
+type UsageFunc func(ctx context.Context, model string, tokens int)
+
+// Config points the client at OpenAI or any OpenAI-compatible embeddings API
+type Config struct {
+	APIKey  string
+	BaseURL string
+	Model   string
+	// Dimensions asks the API for shortened embeddings, 0 keeps the model default
+	Dimensions int
+	// Timeout bounds every single HTTP attempt, 0 uses DefaultTimeout
+	Timeout time.Duration
+	// MaxRetries is the number of retries after a rate limit or server error,
+	// a negative value disables retries and 0 uses DefaultMaxRetries
+	MaxRetries int
+	// OnUsage is called with the usage reported by every response, it may be nil
+	OnUsage UsageFunc
+}
+
 type Client struct {
-	apiKey string
+	apiKey     string
+	baseURL    string
+	model      string
+	dimensions int
+	maxRetries int
+	onUsage    UsageFunc
+	httpClient *http.Client
 }
 
-func NewClient(apiKey string) *Client {
-	return &Client{}
+func NewClient(cfg Config) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = DefaultModel
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	maxRetries := cfg.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	return &Client{
+		apiKey:     cfg.APIKey,
+		baseURL:    baseURL,
+		model:      model,
+		dimensions: cfg.Dimensions,
+		maxRetries: maxRetries,
+		onUsage:    cfg.OnUsage,
+		httpClient: &http.Client{Timeout: timeout},
+	}
 }
 
 type OpenAIEmbeddingRequest struct {
-	Input string `json:"input"`
-	Model string `json:"model"`
+	// Input is either a single string or a list of strings
+	Input      any    `json:"input"`
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions,omitempty"`
 }
 
 type OpenAIEmbeddingResponse struct {
 	Data []struct {
+		Index     int       `json:"index"`
 		Embedding []float64 `json:"embedding"`
 	} `json:"data"`
+	Usage struct {
+		PromptTokens int `json:"prompt_tokens"`
+		TotalTokens  int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// APIError is returned when the API answers with a non 200 status
+type APIError struct {
+	StatusCode int
+	Body       string
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("OpenAI API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether the request may succeed when sent again
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+func (c *Client) Name() string {
+	return "openai"
+}
+
+func (c *Client) Model() string {
+	return c.model
+}
+
+func (c *Client) Dimensions() int {
+	return c.dimensions
 }
 
 // GenerateEmbedding creates a vector embedding for the given text using OpenAI
-func (c *Client) GenerateEmbedding(text string) ([]float64, error) {
-	if c.apiKey == "" {
+func (c *Client) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	embeddings, err := c.generate(ctx, text, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return embeddings[0], nil
+}
+
+// GenerateEmbeddings creates embeddings for several texts in a single request.
+// Callers are expected to keep batches within the API limits, see embedding.SplitBatches.
+func (c *Client) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return [][]float64{}, nil
+	}
+
+	return c.generate(ctx, texts, len(texts))
+}
+
+func (c *Client) generate(ctx context.Context, input any, count int) ([][]float64, error) {
+	if c.apiKey == "" && c.baseURL == DefaultBaseURL {
 		return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
 	}
 
 	requestBody := OpenAIEmbeddingRequest{
-		Input: text,
-		Model: "text-embedding-3-large",
+		Input:      input,
+		Model:      c.model,
+		Dimensions: c.dimensions,
 	}
 
 	jsonData, err := json.Marshal(requestBody)
@@ -43,34 +179,130 @@ func (c *Client) GenerateEmbedding(text string) ([]float64, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/embeddings", bytes.NewBuffer(jsonData))
-	if err != nil {
+	var embeddingResp OpenAIEmbeddingResponse
+	if err := c.post(ctx, "/embeddings", jsonData, &embeddingResp); err != nil {
 		return nil, err
 	}
 
+	// The request is billed even if its response turns out to be unusable
+	if tokens := max(embeddingResp.Usage.TotalTokens, embeddingResp.Usage.PromptTokens); tokens > 0 && c.onUsage != nil {
+		c.onUsage(ctx, c.model, tokens)
+	}
+
+	if len(embeddingResp.Data) == 0 {
+		return nil, fmt.Errorf("no embedding returned from OpenAI")
+	}
+
+	// The API tags every embedding with the position of its input,
+	// do not rely on the order of the data array
+	embeddings := make([][]float64, count)
+	for _, data := range embeddingResp.Data {
+		if data.Index < 0 || data.Index >= count {
+			return nil, fmt.Errorf("OpenAI returned an embedding for unknown input %d", data.Index)
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+
+	for i, embedding := range embeddings {
+		if len(embedding) == 0 {
+			return nil, fmt.Errorf("no embedding returned from OpenAI for input %d", i)
+		}
+	}
+
+	return embeddings, nil
+}
+
+// post sends the request, retrying with exponential backoff on rate limits,
+// server errors and network failures until the context is done
+func (c *Client) post(ctx context.Context, path string, body []byte, out any) error {
+	backoff := initialBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := c.do(ctx, path, body, out)
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil || attempt >= c.maxRetries || !retryable(err) {
+			return err
+		}
+
+		wait := min(backoff, maxBackoff)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.retryAfter > 0 {
+			// Retrying before the server asked only burns a retry on another rate limit
+			if apiErr.retryAfter > maxRetryAfter {
+				return err
+			}
+			wait = apiErr.retryAfter
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		backoff *= 2
+	}
+}
+
+func (c *Client) do(ctx context.Context, path string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, string(body))
+		respBody, _ := io.ReadAll(resp.Body)
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       string(respBody),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
 	}
 
-	var embeddingResp OpenAIEmbeddingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&embeddingResp); err != nil {
-		return nil, err
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
 	}
 
-	if len(embeddingResp.Data) == 0 {
-		return nil, fmt.Errorf("no embedding returned from OpenAI")
+	// Transport failures (timeouts, connection resets...) are worth another try
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled)
+}
+
+// parseRetryAfter accepts both forms of the header: delay in seconds or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
 	}
 
-	return embeddingResp.Data[0].Embedding, nil
+	return 0
 }
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces filename with data without ever leaving a truncated file behind.
+// The data is written to a temporary file in the same folder, flushed to disk and renamed
+// over filename, so a crash keeps either the old or the new content.
+func WriteFileAtomic(filename string, data []byte) error {
+	dirName := filepath.Dir(filename)
+
+	file, err := os.CreateTemp(dirName, filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := file.Name()
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp, filename)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	syncDir(dirName)
+	return nil
+}
+
+// syncDir flushes a folder so that a rename in it survives a power loss. Not every
+// platform can open folders (e.g. Windows), where the rename is already durable.
+func syncDir(dirName string) {
+	dir, err := os.Open(dirName)
+	if err != nil {
+		return
+	}
+	defer dir.Close()
+
+	dir.Sync()
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultBackups is how many backups of each catalog file are kept
+const DefaultBackups = 10
+
+// backupTimeLayout sorts backups by name in the order they were made
+const backupTimeLayout = "20060102-150405.000"
+
+// Backup is a copy of a catalog file made before it was overwritten
+type Backup struct {
+	// Name identifies the backup, e.g. models-20261017-090500.123.json
+	Name string
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// Backups keeps timestamped copies of catalog files in a folder, the oldest beyond keep are deleted
+type Backups struct {
+	dirName string
+	keep    int
+}
+
+// NewBackups keeps up to keep backups of every file in dirName, 0 disables backups
+func NewBackups(dirName string, keep int) *Backups {
+	return &Backups{
+		dirName: dirName,
+		keep:    keep,
+	}
+}
+
+// backup stores data, the current content of filename, before it is overwritten
+func (b *Backups) backup(filename string, data []byte) error {
+	if b == nil || b.keep <= 0 || len(data) == 0 {
+		return nil
+	}
+
+	err := os.MkdirAll(b.dirName, 0755)
+	if err != nil {
+		return err
+	}
+
+	stem, ext := backupStem(filename)
+	name := stem + "-" + time.Now().Format(backupTimeLayout) + ext
+	err = WriteFileAtomic(filepath.Join(b.dirName, name), data)
+	if err != nil {
+		return err
+	}
+
+	backups, err := b.List(filename)
+	if err != nil {
+		return err
+	}
+
+	for _, backup := range backups[min(b.keep, len(backups)):] {
+		err = os.Remove(backup.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List returns the backups of a catalog file, newest first
+func (b *Backups) List(filename string) ([]Backup, error) {
+	if b == nil {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(b.dirName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stem, ext := backupStem(filename)
+	backups := make([]Backup, 0)
+	for _, entry := range entries {
+		timestamp, ok := strings.CutPrefix(entry.Name(), stem+"-")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		timestamp, ok = strings.CutSuffix(timestamp, ext)
+		if !ok {
+			continue
+		}
+
+		t, err := time.ParseInLocation(backupTimeLayout, timestamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, Backup{
+			Name: entry.Name(),
+			Path: filepath.Join(b.dirName, entry.Name()),
+			Time: t,
+			Size: info.Size(),
+		})
+	}
+
+	slices.SortFunc(backups, func(a, b Backup) int {
+		return strings.Compare(b.Name, a.Name)
+	})
+
+	return backups, nil
+}
+
+// Restore replaces a catalog file with one of its backups, by name, or with the newest
+// one when name is empty. The current file is backed up first, so a restore can be undone.
+func (b *Backups) Restore(filename string, name string) (Backup, error) {
+	backups, err := b.List(filename)
+	if err != nil {
+		return Backup{}, err
+	}
+
+	if len(backups) == 0 {
+		return Backup{}, fmt.Errorf("no backups of %s in %s", filepath.Base(filename), b.dirName)
+	}
+
+	i := 0
+	if name != "" {
+		i = slices.IndexFunc(backups, func(backup Backup) bool { return backup.Name == name })
+	}
+	if i < 0 {
+		return Backup{}, fmt.Errorf("no backup %q of %s in %s", name, filepath.Base(filename), b.dirName)
+	}
+	backup := backups[i]
+
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return Backup{}, err
+	}
+	if !json.Valid(data) {
+		return Backup{}, fmt.Errorf("backup %s is not valid JSON", backup.Name)
+	}
+
+	current, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Backup{}, err
+	}
+
+	err = b.backup(filename, current)
+	if err != nil {
+		return Backup{}, fmt.Errorf("error backing up %s before restoring: %w", filepath.Base(filename), err)
+	}
+
+	return backup, WriteFileAtomic(filename, data)
+}
+
+// backupStem splits the name of a catalog file, e.g. models and .json
+func backupStem(filename string) (string, string) {
+	base := filepath.Base(filename)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext), ext
+}
@@ -0,0 +1,748 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"MMDContent/internal/entities"
+)
+
+// A sync holds back removals that would empty most of a catalog, a missing or unmounted
+// folder looks just like every item deleted
+const (
+	maxRemovedShare = 0.5
+	minHeldRemovals = 10
+)
+
+// ErrContentFolderMissing is returned by a sync when the content folder of an existing catalog
+// is gone, the catalog is left alone since its drive may just not be mounted
+var ErrContentFolderMissing = errors.New("content folder is missing")
+
+// ContentSpec describes a kind of content to the generic storage: where its items are
+// kept in the JSON file, how they are read from the folder and how to access their fields
+type ContentSpec[T any] struct {
+	Type entities.ContentType
+	// Key is the property of the JSON file holding the items, e.g. "models"
+	Key string
+	// ReadItem reads the item kept in the folder itemPath, ok is false when it holds none
+	ReadItem func(itemPath, id string) (item T, ok bool)
+	// Files are the entries of an item folder ReadItem reads, the item is read again when one changes
+	Files []string
+	ID    func(item T) string
+	Equal func(a, b T) bool
+	// Source is the original file of an item, it is kept when the item folder is renamed
+	Source func(item T) string
+	// Embeddable returns the text of an item, embedded and indexed by keyword search
+	Embeddable func(item T) entities.Embeddable
+}
+
+// Content keeps the catalog of a kind of content in a JSON file, merged with the
+// items found in its folder. Items are read while a refresh replaces them, mu guards
+// them and syncs run one at a time; the slices are never modified once set.
+type Content[T any] struct {
+	mu       sync.RWMutex
+	syncing  sync.Mutex
+	spec     ContentSpec[T]
+	items    []T
+	byID     map[string]int
+	dirName  string
+	filename string
+	vectors  *Vectors
+	backups  *Backups
+	version  uint64
+	manifest scanManifest
+	startup  entities.CatalogStartup
+	// catalog is the signature of the catalog file when it was last loaded or saved
+	catalog uint64
+	loaded  bool
+}
+
+func NewContentLoaded[T any](
+	spec ContentSpec[T],
+	dirName string,
+	filename string,
+	vectors *Vectors,
+	backups *Backups,
+) (*Content[T], error) {
+	c := &Content[T]{
+		spec:     spec,
+		dirName:  dirName,
+		filename: filename,
+		vectors:  vectors,
+		backups:  backups,
+		manifest: loadScanManifest(manifestFilename(filename)),
+		startup:  entities.CatalogStartup{Type: spec.Type, File: filename},
+	}
+
+	report, err := c.sync(false)
+	if errors.Is(err, ErrContentFolderMissing) {
+		slog.Warn("content folder is missing, keeping the catalog as it is", "type", spec.Type, "folder", dirName)
+		c.startup.MissingFolder = dirName
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.startup.Sync = report
+
+	return c, nil
+}
+
+// Startup returns what happened to the catalog when it was loaded at launch
+func (c *Content[T]) Startup() entities.CatalogStartup {
+	return c.startup
+}
+
+// sync reconciles the catalog with the folder by ID: items of the folder replace the
+// stored ones, missing folders are removed and renamed folders keep their embeddings.
+// Removing most of the catalog at once needs confirmRemovals, see reconcile.
+func (c *Content[T]) sync(confirmRemovals bool) (entities.SyncReport, error) {
+	c.syncing.Lock()
+	defer c.syncing.Unlock()
+
+	itemsInJSON, fromFile, err := c.stored()
+	if err != nil {
+		return entities.SyncReport{}, err
+	}
+
+	itemsInFolder, folders, err := c.scan(itemsInJSON)
+	if errors.Is(err, ErrContentFolderMissing) && fromFile {
+		// The catalog is still usable without its folder
+		c.Set(itemsInJSON)
+	}
+	if err != nil {
+		return entities.SyncReport{}, err
+	}
+
+	items, report := c.reconcile(itemsInJSON, itemsInFolder, confirmRemovals)
+
+	// Embeddings are stored by ID, move the ones of renamed items
+	for _, rename := range report.Renamed {
+		err = c.vectors.Move(c.spec.Type, rename.From, rename.To)
+		if err != nil {
+			return report, fmt.Errorf("error moving the embedding of %s to %s: %w", rename.From, rename.To, err)
+		}
+	}
+
+	// Embeddings are paid for, the ones of items removed now are only deleted by a later
+	// sync that still does not find them, a folder put back in between keeps its embedding
+	err = c.vectors.Delete(c.spec.Type, c.orphans(items, report.Removed)...)
+	if err != nil {
+		return report, fmt.Errorf("error deleting the embeddings of removed items: %w", err)
+	}
+
+	// Replacing the items rebuilds the keyword index, only do it when something changed
+	if fromFile || !report.IsEmpty() {
+		c.Set(items)
+		err = c.Save()
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if c.manifest.Catalog != c.catalog || !maps.Equal(c.manifest.Folders, folders) {
+		c.manifest = scanManifest{Catalog: c.catalog, Folders: folders}
+		err = c.manifest.save(manifestFilename(c.filename))
+		if err != nil {
+			return report, fmt.Errorf("error saving the scan manifest: %w", err)
+		}
+	}
+
+	if len(report.Held) > 0 {
+		slog.Warn("not removing most of the catalog, its folder may be missing or unmounted",
+			"type", c.spec.Type, "folder", c.dirName, "held", len(report.Held))
+	}
+
+	if !report.IsEmpty() {
+		slog.Info("synced catalog with its folder", "type", c.spec.Type,
+			"added", len(report.Added), "updated", len(report.Updated),
+			"removed", len(report.Removed), "renamed", len(report.Renamed))
+	}
+
+	return report, nil
+}
+
+// orphans returns the IDs of the embeddings of items that are neither in the catalog nor removed by this sync
+func (c *Content[T]) orphans(items []T, removed []string) []string {
+	live := make(map[string]bool, len(items)+len(removed))
+	for _, item := range items {
+		live[c.spec.ID(item)] = true
+	}
+	for _, id := range removed {
+		live[id] = true
+	}
+
+	var orphans []string
+	c.vectors.Range(c.spec.Type, func(id string, _ Vector) {
+		if !live[id] {
+			orphans = append(orphans, id)
+		}
+	})
+
+	return orphans
+}
+
+// stored returns the catalog, read from its file unless the file is unchanged since it was
+// last loaded or saved. fromFile reports whether it was read.
+func (c *Content[T]) stored() (items []T, fromFile bool, err error) {
+	signature, err := fileSignature(c.filename)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if c.loaded && signature == c.catalog {
+		return c.Items(), false, nil
+	}
+
+	items, err = c.load()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// A recovered catalog file is not the one the signature was taken of
+	c.catalog, err = fileSignature(c.filename)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return items, true, nil
+}
+
+// scan reads the items of the content folder with the signature of each item folder.
+// Folders unchanged since the last scan are not read again, their item is taken from stored.
+func (c *Content[T]) scan(stored []T) ([]T, map[string]uint64, error) {
+	// Read all directories in the content folder, os.ReadDir sorts them by name
+	entries, err := os.ReadDir(c.dirName)
+	if errors.Is(err, os.ErrNotExist) {
+		// Only a new catalog comes with a new folder, an existing catalog must not be emptied
+		// because its folder is on a drive that is not mounted
+		if c.loaded || c.startup.Recovery != entities.RecoveryCreated {
+			return nil, nil, fmt.Errorf("%w: %s", ErrContentFolderMissing, c.dirName)
+		}
+		err = os.MkdirAll(c.dirName, 0755)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The manifest describes the folders the catalog was made from, not any other catalog
+	storedByID := make(map[string]T)
+	if c.manifest.Catalog == c.catalog {
+		for _, item := range stored {
+			storedByID[c.spec.ID(item)] = item
+		}
+	}
+
+	items := make([]T, 0, len(entries))
+	folders := make(map[string]uint64, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		id := entry.Name()
+		itemPath := filepath.Join(c.dirName, id)
+
+		signature, err := folderSignature(itemPath, c.spec.Files)
+		if err != nil {
+			continue // Skip folders that cannot be read
+		}
+
+		item, ok := storedByID[id]
+		if !ok || c.manifest.Folders[id] != signature {
+			item, ok = c.spec.ReadItem(itemPath, id)
+			if !ok {
+				continue
+			}
+		}
+
+		items = append(items, item)
+		folders[id] = signature
+	}
+
+	return items, folders, nil
+}
+
+// reconcile merges the stored items with the ones read from the folder. Stored items keep
+// their order, duplicated IDs are merged into the first one and new items go at the end.
+// Unless confirmRemovals, items are held instead of removed when the folder holds none
+// or most of the catalog would go.
+func (c *Content[T]) reconcile(stored, scanned []T, confirmRemovals bool) ([]T, entities.SyncReport) {
+	report := entities.SyncReport{
+		Type:    c.spec.Type,
+		Added:   []string{},
+		Updated: []string{},
+		Removed: []string{},
+		Renamed: []entities.SyncRename{},
+		Held:    []string{},
+	}
+
+	scannedByID := make(map[string]T, len(scanned))
+	for _, item := range scanned {
+		scannedByID[c.spec.ID(item)] = item
+	}
+
+	// The last copy of a duplicated item is the most recent one, sync used to append edits
+	storedByID := make(map[string]T, len(stored))
+	duplicated := make(map[string]bool)
+	ids := make([]string, 0, len(stored))
+	for _, item := range stored {
+		id := c.spec.ID(item)
+		if _, ok := storedByID[id]; ok {
+			duplicated[id] = true
+		} else {
+			ids = append(ids, id)
+		}
+		storedByID[id] = item
+	}
+
+	// A folder that is gone and a new folder with the same source is a rename
+	added := make([]string, 0)
+	renamedFrom := make(map[string]string)
+	for _, item := range scanned {
+		id := c.spec.ID(item)
+		if _, ok := storedByID[id]; !ok {
+			added = append(added, id)
+		}
+	}
+
+	bySource := make(map[string]string)
+	for _, id := range ids {
+		if _, ok := scannedByID[id]; !ok {
+			if source := c.spec.Source(storedByID[id]); source != "" {
+				if _, ok := bySource[source]; !ok {
+					bySource[source] = id
+				}
+			}
+		}
+	}
+
+	for _, id := range added {
+		source := c.spec.Source(scannedByID[id])
+		from, ok := bySource[source]
+		if source == "" || !ok {
+			continue
+		}
+
+		delete(bySource, source)
+		renamedFrom[from] = id
+		report.Renamed = append(report.Renamed, entities.SyncRename{From: from, To: id})
+	}
+
+	removed := 0
+	for _, id := range ids {
+		if _, ok := scannedByID[id]; !ok && renamedFrom[id] == "" {
+			removed++
+		}
+	}
+	hold := !confirmRemovals && removed > 0 &&
+		(len(scanned) == 0 || removed >= minHeldRemovals && float64(removed) > maxRemovedShare*float64(len(ids)))
+
+	items := make([]T, 0, len(scanned))
+	kept := make(map[string]bool, len(scanned))
+	for _, id := range ids {
+		if to, ok := renamedFrom[id]; ok {
+			items = append(items, scannedByID[to])
+			kept[to] = true
+			continue
+		}
+
+		item, ok := scannedByID[id]
+		if !ok && hold {
+			items = append(items, storedByID[id])
+			report.Held = append(report.Held, id)
+			continue
+		}
+		if !ok {
+			report.Removed = append(report.Removed, id)
+			continue
+		}
+
+		if duplicated[id] || !c.spec.Equal(storedByID[id], item) {
+			report.Updated = append(report.Updated, id)
+		}
+		items = append(items, item)
+		kept[id] = true
+	}
+
+	for _, id := range added {
+		if !kept[id] {
+			items = append(items, scannedByID[id])
+			report.Added = append(report.Added, id)
+		}
+	}
+
+	return items, report
+}
+
+// Items returns every item, in catalog order
+func (c *Content[T]) Items() []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.items
+}
+
+func (c *Content[T]) Set(items []T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = items
+	c.loaded = true
+	c.version++
+	c.byID = make(map[string]int, len(items))
+	for i, item := range items {
+		c.byID[c.spec.ID(item)] = i
+	}
+}
+
+// Find returns the item with the given ID
+func (c *Content[T]) Find(id string) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	i, ok := c.byID[id]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+
+	return c.items[i], true
+}
+
+func (c *Content[T]) IsEmpty() bool {
+	return c.Total() == 0
+}
+
+func (c *Content[T]) Total() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.items)
+}
+
+// Refresh syncs the catalog with its folder and reports what changed. Removals that
+// would empty most of the catalog are held, see RefreshConfirmed.
+func (c *Content[T]) Refresh() (entities.SyncReport, error) {
+	return c.sync(false)
+}
+
+// RefreshConfirmed syncs the catalog with its folder like Refresh, also removing
+// the items held back because most of the catalog was gone
+func (c *Content[T]) RefreshConfirmed() (entities.SyncReport, error) {
+	return c.sync(true)
+}
+
+// Backups returns the backups of the catalog file, newest first
+func (c *Content[T]) Backups() ([]Backup, error) {
+	return c.backups.List(c.filename)
+}
+
+// Restore replaces the catalog file with one of its backups, the newest one when name
+// is empty, and syncs the catalog restored with its folder. The catalog in use would
+// otherwise overwrite the restored file on its next save.
+func (c *Content[T]) Restore(name string) (Backup, entities.SyncReport, error) {
+	c.syncing.Lock()
+	backup, err := c.backups.Restore(c.filename, name)
+	// The next sync reads the file again even if its signature looks unchanged
+	c.catalog = 0
+	c.syncing.Unlock()
+	if err != nil {
+		return Backup{}, entities.SyncReport{}, err
+	}
+
+	report, err := c.sync(false)
+	return backup, report, err
+}
+
+// Save writes the catalog file atomically, after backing up its previous content
+func (c *Content[T]) Save() error {
+	jsonData, err := json.MarshalIndent(map[string][]T{c.spec.Key: c.Items()}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(c.filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// An unchanged catalog is neither backed up nor written again, e.g. on every launch
+	if !bytes.Equal(current, jsonData) {
+		err = c.backups.backup(c.filename, current)
+		if err != nil {
+			return fmt.Errorf("error backing up %s: %w", c.filename, err)
+		}
+
+		err = WriteFileAtomic(c.filename, jsonData)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.catalog, err = fileSignature(c.filename)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ContentType identifies the kind of content held by the storage
+func (c *Content[T]) ContentType() entities.ContentType {
+	return c.spec.Type
+}
+
+// Embeddables returns the text of every item, embedded and indexed by keyword search
+func (c *Content[T]) Embeddables() []entities.Embeddable {
+	items := c.Items()
+	embeddables := make([]entities.Embeddable, len(items))
+	for i, item := range items {
+		embeddables[i] = c.spec.Embeddable(item)
+	}
+
+	return embeddables
+}
+
+// Version changes every time the items are replaced, e.g. on Refresh
+func (c *Content[T]) Version() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.version
+}
+
+// GetPaginated returns a paginated subset of the items for which match returns true,
+// of every item when match is nil
+func (c *Content[T]) GetPaginated(page, perPage int, match func(T) bool) entities.Pagination[T] {
+	items := c.Items()
+	if match != nil {
+		all := items
+		items = make([]T, 0)
+		for _, item := range all {
+			if match(item) {
+				items = append(items, item)
+			}
+		}
+	}
+	total := len(items)
+
+	// Calculate pagination
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 100
+	}
+
+	totalPages := (total + perPage - 1) / perPage
+	if page > totalPages {
+		page = max(totalPages, 1)
+	}
+
+	start := (page - 1) * perPage
+	end := start + perPage
+
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+
+	return entities.Pagination[T]{
+		Data:       items[start:end],
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+	}
+}
+
+// load reads the catalog file. A missing file is an empty catalog, which the folder scan
+// fills and sync saves. A corrupt one is recovered.
+func (c *Content[T]) load() ([]T, error) {
+	jsonData, err := os.ReadFile(c.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		c.recovered(entities.CatalogStartup{Recovery: entities.RecoveryCreated})
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Only a file that does not parse is corrupt, other errors leave it alone
+	items, err := c.decode(jsonData)
+	if err != nil {
+		items, jsonData, err = c.recover(err)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Embeddings used to be stored inline, move them to the vector store
+	if jsonData != nil {
+		_, err = c.vectors.migrateInlineEmbeddings(jsonData, c.spec.Key, c.spec.Type)
+		if err != nil {
+			return nil, fmt.Errorf("error moving the embeddings of %s to the vector store: %w", c.filename, err)
+		}
+	}
+
+	return items, nil
+}
+
+// decode parses the content of a catalog file
+func (c *Content[T]) decode(jsonData []byte) ([]T, error) {
+	var data map[string][]T
+	err := json.Unmarshal(jsonData, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data[c.spec.Key], nil
+}
+
+// recover replaces a corrupt catalog file with its newest valid backup. Without one the
+// catalog starts empty and the folder scan rebuilds it. The corrupt file is moved aside
+// rather than deleted, it may still hold something worth recovering by hand. jsonData is
+// the content of the backup restored, nil when rebuilding.
+func (c *Content[T]) recover(cause error) (items []T, jsonData []byte, err error) {
+	stem, ext := backupStem(c.filename)
+	corruptFile := filepath.Join(filepath.Dir(c.filename), stem+".corrupt-"+time.Now().Format(backupTimeLayout)+ext)
+	err = os.Rename(c.filename, corruptFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error moving corrupt catalog %s aside: %w", c.filename, err)
+	}
+
+	recovery := entities.CatalogStartup{
+		Recovery:    entities.RecoveryRebuilt,
+		CorruptFile: corruptFile,
+		Error:       cause.Error(),
+	}
+
+	backups, err := c.backups.List(c.filename)
+	if err != nil {
+		slog.Warn("error listing catalog backups", "file", c.filename, "error", err)
+	}
+
+	for _, backup := range backups {
+		jsonData, err := os.ReadFile(backup.Path)
+		if err != nil {
+			continue
+		}
+
+		items, err := c.decode(jsonData)
+		if err != nil {
+			continue
+		}
+
+		err = WriteFileAtomic(c.filename, jsonData)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		recovery.Recovery = entities.RecoveryRestored
+		recovery.Backup = backup.Name
+		c.recovered(recovery)
+		return items, jsonData, nil
+	}
+
+	c.recovered(recovery)
+	return nil, nil, nil
+}
+
+// recovered logs a recovery of the catalog file and adds it to the startup report while loading
+func (c *Content[T]) recovered(recovery entities.CatalogStartup) {
+	if recovery.Recovery == entities.RecoveryCreated {
+		slog.Info("creating missing catalog file", "file", c.filename)
+	} else {
+		slog.Warn("recovered corrupt catalog file", "file", c.filename, "recovery", recovery.Recovery,
+			"backup", recovery.Backup, "corruptFile", recovery.CorruptFile, "error", recovery.Error)
+	}
+
+	if c.loaded {
+		return
+	}
+
+	c.startup.Recovery = recovery.Recovery
+	c.startup.Backup = recovery.Backup
+	c.startup.CorruptFile = recovery.CorruptFile
+	c.startup.Error = recovery.Error
+}
+
+// folderFiles are the entries of an item folder read by folderReader
+var folderFiles = []string{"ruta.txt", "descripcion.txt", "screenshots"}
+
+// folderItem is what every item folder holds: ruta.txt with the original path,
+// descripcion.txt and a screenshots folder
+type folderItem struct {
+	ID           string
+	Name         string
+	Description  string
+	OriginalPath string
+	Screenshots  []string
+	// Path is the folder of the item, to read extra files from
+	Path string
+}
+
+// folderReader returns a ContentSpec.ReadItem for the item folders, which build converts.
+// Folders without ruta.txt hold no item.
+func folderReader[T any](build func(item folderItem) T) func(itemPath, id string) (T, bool) {
+	return func(itemPath, id string) (T, bool) {
+		// Read ruta.txt
+		rutaContent, err := os.ReadFile(filepath.Join(itemPath, "ruta.txt"))
+		if err != nil {
+			var zero T
+			return zero, false // Skip if ruta.txt doesn't exist
+		}
+
+		// Extract filename from path
+		rutaStr := strings.TrimSpace(string(rutaContent))
+
+		// Read descripcion.txt
+		description := ""
+		descContent, err := os.ReadFile(filepath.Join(itemPath, "descripcion.txt"))
+		if err == nil {
+			description = string(descContent)
+		}
+
+		return build(folderItem{
+			ID:           id,
+			Name:         filepath.Base(rutaStr),
+			Description:  description,
+			OriginalPath: rutaStr,
+			Screenshots:  readFolderFiles(filepath.Join(itemPath, "screenshots")),
+			Path:         itemPath,
+		}), true
+	}
+}
+
+// readFolderFiles returns the sorted absolute paths of the files in dirName, none when it is missing
+func readFolderFiles(dirName string) []string {
+	var files []string
+	entries, err := os.ReadDir(dirName)
+	if err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				// Store absolute path
+				absPath, err := filepath.Abs(filepath.Join(dirName, entry.Name()))
+				if err == nil {
+					files = append(files, absPath)
+				}
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"MMDContent/internal/entities"
+)
+
+// EmbeddingRuns persists the state of the current embedding generation run
+type EmbeddingRuns struct {
+	filename string
+}
+
+func NewEmbeddingRuns(filename string) *EmbeddingRuns {
+	return &EmbeddingRuns{
+		filename: filename,
+	}
+}
+
+// Load returns the unfinished run, or nil if the last run completed
+func (r *EmbeddingRuns) Load() (*entities.EmbeddingRun, error) {
+	jsonData, err := os.ReadFile(r.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var run entities.EmbeddingRun
+	err = json.Unmarshal(jsonData, &run)
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func (r *EmbeddingRuns) Save(run *entities.EmbeddingRun) error {
+	jsonData, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(r.filename, jsonData)
+}
+
+// Clear forgets the run once it has completed
+func (r *EmbeddingRuns) Clear() error {
+	err := os.Remove(r.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/gob"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// DefaultQueryEmbeddingsCapacity is the number of query embeddings kept by default
+const DefaultQueryEmbeddingsCapacity = 500
+
+// QueryEmbeddings is a least recently used cache of search query embeddings,
+// persisted between sessions so that repeated searches need no API call
+type QueryEmbeddings struct {
+	mu       sync.Mutex
+	filename string
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+	changed  bool
+}
+
+type queryEmbedding struct {
+	Key    string
+	Values []float32
+}
+
+// NewQueryEmbeddingsLoaded loads the cache saved in filename, if any
+func NewQueryEmbeddingsLoaded(filename string, capacity int) (*QueryEmbeddings, error) {
+	if capacity <= 0 {
+		capacity = DefaultQueryEmbeddingsCapacity
+	}
+
+	c := &QueryEmbeddings{
+		filename: filename,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+
+	file, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Saved most recently used first
+	var saved []queryEmbedding
+	if err := gob.NewDecoder(file).Decode(&saved); err != nil {
+		// The cache can always be rebuilt, start afresh rather than failing
+		slog.Warn("discarding unreadable query embedding cache", "file", filename, "error", err)
+		return c, nil
+	}
+	for _, entry := range saved {
+		if c.order.Len() == c.capacity {
+			break
+		}
+		c.entries[entry.Key] = c.order.PushBack(entry)
+	}
+
+	return c, nil
+}
+
+// Get returns the embedding cached under key and marks it as recently used
+func (c *QueryEmbeddings) Get(key string) ([]float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	c.changed = true
+
+	stored := element.Value.(queryEmbedding).Values
+	values := make([]float64, len(stored))
+	for i, value := range stored {
+		values[i] = float64(value)
+	}
+
+	return values, true
+}
+
+// Put caches an embedding, evicting the least recently used one when full
+func (c *QueryEmbeddings) Put(key string, values []float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := queryEmbedding{Key: key, Values: make([]float32, len(values))}
+	for i, value := range values {
+		entry.Values[i] = float32(value)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+	} else {
+		c.entries[key] = c.order.PushFront(entry)
+	}
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(queryEmbedding).Key)
+	}
+	c.changed = true
+}
+
+// Save writes the cache to disk if it changed since it was loaded or last saved
+func (c *QueryEmbeddings) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.changed {
+		return nil
+	}
+
+	saved := make([]queryEmbedding, 0, c.order.Len())
+	for element := c.order.Front(); element != nil; element = element.Next() {
+		saved = append(saved, element.Value.(queryEmbedding))
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(saved); err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(c.filename, buf.Bytes()); err != nil {
+		return err
+	}
+
+	c.changed = false
+	return nil
+}
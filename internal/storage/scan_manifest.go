@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// scanManifest remembers the signature of every item folder at the last scan, so that
+// a refresh only re-reads the folders that changed since
+type scanManifest struct {
+	// Catalog is the signature of the catalog file written with the manifest. The folders
+	// are only trusted while it matches, the catalog holds the items read from them.
+	Catalog uint64            `json:"catalog"`
+	Folders map[string]uint64 `json:"folders"`
+}
+
+// manifestFilename returns where the manifest of a catalog file is kept, e.g. data/models_scan.json
+func manifestFilename(catalogFilename string) string {
+	return strings.TrimSuffix(catalogFilename, filepath.Ext(catalogFilename)) + "_scan.json"
+}
+
+// loadScanManifest reads a manifest, a missing or unreadable one is empty and makes the next scan full
+func loadScanManifest(filename string) scanManifest {
+	manifest := scanManifest{Folders: map[string]uint64{}}
+
+	jsonData, err := os.ReadFile(filename)
+	if err != nil {
+		return manifest
+	}
+
+	if json.Unmarshal(jsonData, &manifest) != nil || manifest.Folders == nil {
+		return scanManifest{Folders: map[string]uint64{}}
+	}
+
+	return manifest
+}
+
+func (m scanManifest) save(filename string) error {
+	jsonData, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(filename, jsonData)
+}
+
+// fileSignature hashes the modification time and size of a file, 0 when it does not exist
+func fileSignature(filename string) (uint64, error) {
+	info, err := os.Stat(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	h := fnv.New64a()
+	writeStatSignature(h, info)
+	return h.Sum64(), nil
+}
+
+// folderSignature hashes the modification times and sizes of the entries of an item
+// folder its item is read from. Editing a file changes its own time, adding or removing
+// screenshots changes the time of their folder, so the files never have to be read.
+func folderSignature(dirName string, files []string) (uint64, error) {
+	h := fnv.New64a()
+	for _, name := range files {
+		h.Write([]byte(name))
+
+		info, err := os.Stat(filepath.Join(dirName, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		writeStatSignature(h, info)
+	}
+
+	return h.Sum64(), nil
+}
+
+func writeStatSignature(h hash.Hash, info os.FileInfo) {
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:8], uint64(info.ModTime().UnixNano()))
+	binary.LittleEndian.PutUint64(buf[8:], uint64(info.Size()))
+	h.Write(buf[:])
+}
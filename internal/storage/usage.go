@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"MMDContent/internal/entities"
+)
+
+// Only recent history is kept, older usage does not count towards any budget
+const (
+	maxUsageDays = 400
+	maxUsageRuns = 50
+)
+
+// Usage persists the tokens spent on embeddings per day and per generation run
+type Usage struct {
+	mu       sync.Mutex
+	filename string
+	data     usageData
+	// recovery is set when the file was corrupt, see Startup
+	recovery *entities.FileStartup
+}
+
+type usageData struct {
+	Days []entities.DailyUsage `json:"days"`
+	Runs []entities.RunUsage   `json:"runs"`
+}
+
+func NewUsageLoaded(filename string) (*Usage, error) {
+	u := &Usage{
+		filename: filename,
+	}
+
+	jsonData, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return u, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(jsonData, &u.data)
+	if err != nil {
+		err = u.recover(err)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return u, nil
+}
+
+// recover moves a corrupt usage file aside and starts again without usage, the spend
+// it recorded is only missing from the budgets and reports
+func (u *Usage) recover(cause error) error {
+	stem, ext := backupStem(u.filename)
+	corruptFile := filepath.Join(filepath.Dir(u.filename), stem+".corrupt-"+time.Now().Format(backupTimeLayout)+ext)
+	err := os.Rename(u.filename, corruptFile)
+	if err != nil {
+		return fmt.Errorf("error moving corrupt usage file %s aside: %w", u.filename, err)
+	}
+
+	slog.Warn("recovered corrupt usage file", "file", u.filename, "corruptFile", corruptFile, "error", cause)
+
+	u.data = usageData{}
+	u.recovery = &entities.FileStartup{
+		File:        u.filename,
+		CorruptFile: corruptFile,
+		Error:       cause.Error(),
+		Lost:        "the embedding usage recorded so far, budgets start again from zero",
+	}
+	return nil
+}
+
+// Startup reports whether the file was corrupt when it was loaded, and how it was recovered
+func (u *Usage) Startup() (entities.FileStartup, bool) {
+	if u.recovery == nil {
+		return entities.FileStartup{}, false
+	}
+
+	return *u.recovery, true
+}
+
+// Record adds the tokens billed for a model to a day (YYYY-MM-DD) and, unless run
+// is zero, to the generation run started at that time
+func (u *Usage) Record(day string, run time.Time, model string, tokens int, cost float64) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	i := slices.IndexFunc(u.data.Days, func(d entities.DailyUsage) bool { return d.Date == day })
+	if i < 0 {
+		u.data.Days = append(u.data.Days, entities.DailyUsage{Date: day})
+		slices.SortFunc(u.data.Days, func(a, b entities.DailyUsage) int { return strings.Compare(a.Date, b.Date) })
+		i = slices.IndexFunc(u.data.Days, func(d entities.DailyUsage) bool { return d.Date == day })
+	}
+	u.data.Days[i].Tokens += tokens
+	u.data.Days[i].Cost += cost
+	u.data.Days[i].Models = addModelUsage(u.data.Days[i].Models, model, tokens, cost)
+	if len(u.data.Days) > maxUsageDays {
+		u.data.Days = slices.Delete(u.data.Days, 0, len(u.data.Days)-maxUsageDays)
+	}
+
+	if !run.IsZero() {
+		r := slices.IndexFunc(u.data.Runs, func(r entities.RunUsage) bool { return r.StartedAt.Equal(run) })
+		if r < 0 {
+			u.data.Runs = append(u.data.Runs, entities.RunUsage{StartedAt: run})
+			r = len(u.data.Runs) - 1
+		}
+		u.data.Runs[r].UpdatedAt = time.Now()
+		u.data.Runs[r].Tokens += tokens
+		u.data.Runs[r].Cost += cost
+		u.data.Runs[r].Models = addModelUsage(u.data.Runs[r].Models, model, tokens, cost)
+		if len(u.data.Runs) > maxUsageRuns {
+			u.data.Runs = slices.Delete(u.data.Runs, 0, len(u.data.Runs)-maxUsageRuns)
+		}
+	}
+
+	return u.save()
+}
+
+// Days returns the days whose date starts with prefix, e.g. a month (YYYY-MM), oldest first
+func (u *Usage) Days(prefix string) []entities.DailyUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	days := make([]entities.DailyUsage, 0)
+	for _, day := range u.data.Days {
+		if strings.HasPrefix(day.Date, prefix) {
+			day.Models = slices.Clone(day.Models)
+			days = append(days, day)
+		}
+	}
+
+	return days
+}
+
+// Runs returns the latest generation runs, most recent first
+func (u *Usage) Runs() []entities.RunUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	runs := make([]entities.RunUsage, 0, len(u.data.Runs))
+	for i := len(u.data.Runs) - 1; i >= 0; i-- {
+		run := u.data.Runs[i]
+		run.Models = slices.Clone(run.Models)
+		runs = append(runs, run)
+	}
+
+	return runs
+}
+
+// save writes atomically, a crash must not lose the spend recorded so far
+func (u *Usage) save() error {
+	jsonData, err := json.MarshalIndent(u.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(u.filename, jsonData)
+}
+
+func addModelUsage(models []entities.ModelUsage, model string, tokens int, cost float64) []entities.ModelUsage {
+	for i := range models {
+		if models[i].Model == model {
+			models[i].Tokens += tokens
+			models[i].Cost += cost
+			return models
+		}
+	}
+
+	return append(models, entities.ModelUsage{Model: model, Tokens: tokens, Cost: cost})
+}
@@ -0,0 +1,801 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"MMDContent/internal/entities"
+	"MMDContent/internal/quantize"
+)
+
+// The vector file is an append-only log of records after a fixed header:
+//
+//	header: "MMDV" | version (1 byte) | 3 reserved bytes
+//	record: payload length (uint32) | payload | CRC-32 of payload (uint32)
+//	payload: op (1 byte) | content type | id | [put: provider | model | text hash | vector]
+//	         [put chunks: provider | model | text hash | count (uvarint) |
+//	         count * (start (uvarint) | end (uvarint) | vector)]
+//	         [put fields: provider | model | text hash | count (uvarint) |
+//	         count * (field | start (uvarint) | end (uvarint) | vector)]
+//	vector: encoding (1 byte) | dimensions (uint32) | [int8 only: scale (float32)] | values
+//
+// Values are float32, float16 or int8 (see quantize.Encoding), all vectors are
+// rewritten in the configured encoding when it changes.
+// Strings are uvarint length prefixed, numbers are little endian. A partial record at the
+// end of the file (a torn write) is dropped on load. A corrupt record before it is copied
+// aside with the whole file, and the log starts again from the records before it.
+// Files of older versions only hold a subset of the operations and are read as they are.
+const (
+	vectorsMagic   = "MMDV"
+	vectorsVersion = 3
+
+	vectorOpPut       = 1
+	vectorOpDelete    = 2
+	vectorOpPutChunks = 3
+	vectorOpPutFields = 4
+)
+
+var vectorsHeader = []byte{'M', 'M', 'D', 'V', vectorsVersion, 0, 0, 0}
+
+var (
+	// errVectorRecordPartial is a record cut short by the end of the file, e.g. by a crash during an append
+	errVectorRecordPartial = errors.New("partial vector record")
+	// errVectorRecordCorrupt is a record whose CRC does not match with more records after it
+	errVectorRecordCorrupt = errors.New("corrupt vector record")
+)
+
+// Vector is a stored embedding. Info.Provider is empty for legacy embeddings
+// that were saved before fingerprints existed.
+type Vector struct {
+	Info   entities.EmbeddingInfo
+	Chunks []VectorChunk
+}
+
+// VectorChunk holds the values of a field or of a passage of the description,
+// see entities.EmbeddingChunk
+type VectorChunk struct {
+	Field  string
+	Start  int
+	End    int
+	Values quantize.Vector
+}
+
+// Chunk returns the chunk of the vector at index i, if any
+func (v Vector) Chunk(i int) (VectorChunk, bool) {
+	if i < 0 || i >= len(v.Chunks) {
+		return VectorChunk{}, false
+	}
+
+	return v.Chunks[i], true
+}
+
+// VectorChange describes a stored or deleted vector, Vector is nil on deletion
+type VectorChange struct {
+	ContentType entities.ContentType
+	ID          string
+	Vector      *Vector
+}
+
+// Vectors stores embeddings in a compact binary file, keyed by content type and ID.
+// The file is loaded once and every change is appended to it.
+type Vectors struct {
+	mu        sync.RWMutex
+	filename  string
+	file      *os.File
+	encoding  quantize.Encoding
+	data      map[entities.ContentType]map[string]Vector
+	dead      int
+	reencoded bool
+	// recovery is set when the file was corrupt, see Startup
+	recovery  *entities.FileStartup
+	listeners []func([]VectorChange)
+}
+
+// NewVectorsLoaded loads the vectors, keeping their values in the given encoding
+func NewVectorsLoaded(filename string, encoding quantize.Encoding) (*Vectors, error) {
+	v := &Vectors{
+		filename: filename,
+		encoding: encoding,
+		data:     make(map[entities.ContentType]map[string]Vector),
+	}
+
+	err := v.load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Rewrite the file when most of it is made of overwritten or deleted records,
+	// when the encoding changed or when it was corrupt
+	if v.dead > v.live() || v.reencoded || v.recovery != nil {
+		if err := v.compact(); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	v.file = file
+
+	return v, nil
+}
+
+// Encoding returns how values are stored
+func (v *Vectors) Encoding() quantize.Encoding {
+	return v.encoding
+}
+
+// Get returns the vector of an item
+func (v *Vectors) Get(contentType entities.ContentType, id string) (Vector, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	vector, ok := v.data[contentType][id]
+	return vector, ok
+}
+
+// Range calls fn for every vector of a content type. fn must not modify the store.
+func (v *Vectors) Range(contentType entities.ContentType, fn func(id string, vector Vector)) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	for id, vector := range v.data[contentType] {
+		fn(id, vector)
+	}
+}
+
+// Subscribe registers fn to be called after every change, e.g. to keep an index in sync
+func (v *Vectors) Subscribe(fn func(changes []VectorChange)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.listeners = append(v.listeners, fn)
+}
+
+// Count returns the number of vectors of a content type
+func (v *Vectors) Count(contentType entities.ContentType) int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return len(v.data[contentType])
+}
+
+// Put stores embeddings keyed by item ID, replacing existing ones
+func (v *Vectors) Put(contentType entities.ContentType, embeddings map[string]entities.Embedding) error {
+	vectors := make(map[string]Vector, len(embeddings))
+	for id, embedding := range embeddings {
+		chunks := make([]VectorChunk, len(embedding.Chunks))
+		for c, chunk := range embedding.Chunks {
+			values := make([]float32, len(chunk.Vector))
+			for i, value := range chunk.Vector {
+				values[i] = float32(value)
+			}
+			chunks[c] = VectorChunk{
+				Field:  chunk.Field,
+				Start:  chunk.Start,
+				End:    chunk.End,
+				Values: quantize.Encode(values, v.encoding),
+			}
+		}
+		vectors[id] = Vector{Info: embedding.Info, Chunks: chunks}
+	}
+
+	return v.put(contentType, vectors)
+}
+
+// Move gives the vector of an item to another ID, e.g. when its folder was renamed
+func (v *Vectors) Move(contentType entities.ContentType, from, to string) error {
+	v.mu.RLock()
+	vector, ok := v.data[contentType][from]
+	v.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	err := v.put(contentType, map[string]Vector{to: vector})
+	if err != nil {
+		return err
+	}
+
+	return v.Delete(contentType, from)
+}
+
+// Delete removes the vectors of the given items
+func (v *Vectors) Delete(contentType entities.ContentType, ids ...string) error {
+	changes, err := v.delete(contentType, ids)
+	if err != nil {
+		return err
+	}
+
+	v.notify(changes)
+	return nil
+}
+
+func (v *Vectors) delete(contentType entities.ContentType, ids []string) ([]VectorChange, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var buf bytes.Buffer
+	for _, id := range ids {
+		if _, ok := v.data[contentType][id]; !ok {
+			continue
+		}
+		writeVectorRecord(&buf, encodeVectorDelete(contentType, id))
+	}
+
+	if buf.Len() == 0 {
+		return nil, nil
+	}
+
+	if err := v.append(buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	var changes []VectorChange
+	for _, id := range ids {
+		if _, ok := v.data[contentType][id]; ok {
+			delete(v.data[contentType], id)
+			v.dead += 2
+			changes = append(changes, VectorChange{ContentType: contentType, ID: id})
+		}
+	}
+
+	return changes, nil
+}
+
+// Close releases the underlying file
+func (v *Vectors) Close() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.file == nil {
+		return nil
+	}
+
+	err := v.file.Close()
+	v.file = nil
+	return err
+}
+
+func (v *Vectors) put(contentType entities.ContentType, vectors map[string]Vector) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	changes, err := v.write(contentType, vectors)
+	if err != nil {
+		return err
+	}
+
+	v.notify(changes)
+	return nil
+}
+
+func (v *Vectors) write(contentType entities.ContentType, vectors map[string]Vector) ([]VectorChange, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var buf bytes.Buffer
+	for id, vector := range vectors {
+		writeVectorRecord(&buf, encodeVectorPut(contentType, id, vector))
+	}
+
+	if err := v.append(buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	items := v.items(contentType)
+	changes := make([]VectorChange, 0, len(vectors))
+	for id, vector := range vectors {
+		if _, ok := items[id]; ok {
+			v.dead++
+		}
+		items[id] = vector
+		changes = append(changes, VectorChange{ContentType: contentType, ID: id, Vector: &vector})
+	}
+
+	return changes, nil
+}
+
+// notify runs the listeners outside of the lock so they can read the store
+func (v *Vectors) notify(changes []VectorChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	v.mu.RLock()
+	listeners := v.listeners
+	v.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(changes)
+	}
+}
+
+// append writes records at the end of the file and flushes them to disk
+func (v *Vectors) append(records []byte) error {
+	if v.file == nil {
+		return fmt.Errorf("vector store %s is closed", v.filename)
+	}
+
+	if _, err := v.file.Write(records); err != nil {
+		return err
+	}
+
+	return v.file.Sync()
+}
+
+func (v *Vectors) items(contentType entities.ContentType) map[string]Vector {
+	items, ok := v.data[contentType]
+	if !ok {
+		items = make(map[string]Vector)
+		v.data[contentType] = items
+	}
+
+	return items
+}
+
+func (v *Vectors) live() int {
+	total := 0
+	for _, items := range v.data {
+		total += len(items)
+	}
+
+	return total
+}
+
+// load replays the log, creating the file if it does not exist yet
+func (v *Vectors) load() error {
+	content, err := os.ReadFile(v.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.WriteFile(v.filename, vectorsHeader, 0644)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(content) < len(vectorsHeader) || string(content[:4]) != vectorsMagic {
+		return v.recover(content, 0, errors.New("not a vector file"))
+	}
+	if content[4] > vectorsVersion {
+		return fmt.Errorf("%s was written by a newer version (format %d)", v.filename, content[4])
+	}
+
+	offset := len(vectorsHeader)
+	for offset < len(content) {
+		payload, size, err := readVectorRecord(content[offset:])
+		if errors.Is(err, errVectorRecordPartial) {
+			break
+		}
+		if err != nil {
+			return v.recover(content, offset, err)
+		}
+
+		if err := v.apply(payload); err != nil {
+			return v.recover(content, offset, err)
+		}
+		offset += size
+	}
+
+	if offset < len(content) {
+		slog.Warn("dropping incomplete records at the end of the vector file", "file", v.filename, "bytes", len(content)-offset)
+		if err := os.Truncate(v.filename, int64(offset)); err != nil {
+			return err
+		}
+	}
+
+	// Records of the current format may be appended, older versions must not read the file anymore
+	if content[4] < vectorsVersion {
+		return v.upgradeHeader()
+	}
+
+	return nil
+}
+
+// recover stops the load at a corrupt record in the middle of the file, keeping the
+// records before it. The file is copied aside first, since the records after the corrupt
+// one may still be fine and worth recovering by hand; the missing vectors are otherwise
+// generated again. The caller rewrites the file from the records kept.
+func (v *Vectors) recover(content []byte, offset int, cause error) error {
+	corruptFile := v.filename + ".corrupt-" + time.Now().Format(backupTimeLayout)
+	err := WriteFileAtomic(corruptFile, content)
+	if err != nil {
+		return fmt.Errorf("%s: %w at offset %d, copying it aside failed: %w", v.filename, cause, offset, err)
+	}
+
+	slog.Warn("recovered corrupt vector file", "file", v.filename, "offset", offset,
+		"bytes", len(content)-offset, "corruptFile", corruptFile, "error", cause)
+
+	lost := "the embeddings stored after the corrupt record"
+	if offset == 0 {
+		lost = "every embedding"
+	}
+	v.recovery = &entities.FileStartup{
+		File:        v.filename,
+		CorruptFile: corruptFile,
+		Error:       fmt.Sprintf("%v at offset %d", cause, offset),
+		Lost:        lost + ", generate embeddings to replace them",
+	}
+	return nil
+}
+
+// Startup reports whether the file was corrupt when it was loaded, and how it was recovered
+func (v *Vectors) Startup() (entities.FileStartup, bool) {
+	if v.recovery == nil {
+		return entities.FileStartup{}, false
+	}
+
+	return *v.recovery, true
+}
+
+func (v *Vectors) upgradeHeader() error {
+	file, err := os.OpenFile(v.filename, os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.WriteAt(vectorsHeader, 0); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (v *Vectors) apply(payload []byte) error {
+	r := bytes.NewReader(payload)
+
+	op, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	contentType, err := readVectorString(r)
+	if err != nil {
+		return err
+	}
+	id, err := readVectorString(r)
+	if err != nil {
+		return err
+	}
+
+	items := v.items(entities.ContentType(contentType))
+	switch op {
+	case vectorOpPut, vectorOpPutChunks, vectorOpPutFields:
+		vector, err := decodeVector(r, op)
+		if err != nil {
+			return err
+		}
+		for i, chunk := range vector.Chunks {
+			if chunk.Values.Encoding != v.encoding {
+				vector.Chunks[i].Values = quantize.Encode(chunk.Values.Floats(), v.encoding)
+				v.reencoded = true
+			}
+		}
+		if _, ok := items[id]; ok {
+			v.dead++
+		}
+		items[id] = vector
+	case vectorOpDelete:
+		if _, ok := items[id]; ok {
+			delete(items, id)
+			v.dead++
+		}
+		v.dead++
+	default:
+		return fmt.Errorf("unknown operation %d", op)
+	}
+
+	return nil
+}
+
+// compact rewrites the file with only the live vectors
+func (v *Vectors) compact() error {
+	var buf bytes.Buffer
+	buf.Write(vectorsHeader)
+	for contentType, items := range v.data {
+		for id, vector := range items {
+			writeVectorRecord(&buf, encodeVectorPut(contentType, id, vector))
+		}
+	}
+
+	if err := WriteFileAtomic(v.filename, buf.Bytes()); err != nil {
+		return err
+	}
+
+	v.dead = 0
+	v.reencoded = false
+	return nil
+}
+
+// migrateInlineEmbeddings moves embeddings stored inside a catalog JSON file (the format used
+// before the vector store existed) into the store. key is the JSON array holding the items.
+// It reports whether anything was migrated; the catalog must then be saved to drop them.
+func (v *Vectors) migrateInlineEmbeddings(jsonData []byte, key string, contentType entities.ContentType) (bool, error) {
+	var catalog map[string][]struct {
+		ID            string                  `json:"id"`
+		Embedding     []float64               `json:"embedding"`
+		EmbeddingInfo *entities.EmbeddingInfo `json:"embeddingInfo"`
+	}
+	if err := json.Unmarshal(jsonData, &catalog); err != nil {
+		return false, err
+	}
+
+	embeddings := make(map[string]entities.Embedding)
+	for _, item := range catalog[key] {
+		if len(item.Embedding) == 0 {
+			continue
+		}
+		// A vector already in the store is newer than the inline one
+		if _, ok := v.Get(contentType, item.ID); ok {
+			continue
+		}
+
+		info := entities.EmbeddingInfo{Dimensions: len(item.Embedding)}
+		if item.EmbeddingInfo != nil {
+			info = *item.EmbeddingInfo
+		}
+		embeddings[item.ID] = entities.Embedding{
+			Chunks: []entities.EmbeddingChunk{{Vector: item.Embedding}},
+			Info:   info,
+		}
+	}
+
+	return len(embeddings) > 0, v.Put(contentType, embeddings)
+}
+
+func writeVectorRecord(w *bytes.Buffer, payload []byte) {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(payload)))
+	w.Write(size[:])
+	w.Write(payload)
+
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], crc32.ChecksumIEEE(payload))
+	w.Write(sum[:])
+}
+
+// readVectorRecord returns the payload of the record at the start of data and the
+// record size. A record that does not fit in data, or the last one when its CRC does
+// not match, is errVectorRecordPartial; any other CRC mismatch is errVectorRecordCorrupt.
+func readVectorRecord(data []byte) ([]byte, int, error) {
+	if len(data) < 8 {
+		return nil, 0, errVectorRecordPartial
+	}
+
+	size := int(binary.LittleEndian.Uint32(data))
+	if size > len(data)-8 {
+		return nil, 0, errVectorRecordPartial
+	}
+
+	payload := data[4 : 4+size]
+	if binary.LittleEndian.Uint32(data[4+size:]) != crc32.ChecksumIEEE(payload) {
+		if size+8 == len(data) {
+			return nil, 0, errVectorRecordPartial
+		}
+		return nil, 0, errVectorRecordCorrupt
+	}
+
+	return payload, size + 8, nil
+}
+
+// encodeVectorPut writes a vector with the simplest put op able to hold it, so that
+// files only grow the newer formats for items that need them
+func encodeVectorPut(contentType entities.ContentType, id string, vector Vector) []byte {
+	op := byte(vectorOpPut)
+	for _, chunk := range vector.Chunks {
+		if chunk.Field != entities.EmbeddingFieldText {
+			op = vectorOpPutFields
+			break
+		}
+		if len(vector.Chunks) != 1 || chunk.Start != 0 || chunk.End != 0 {
+			op = vectorOpPutChunks
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(op)
+	writeVectorString(&buf, string(contentType))
+	writeVectorString(&buf, id)
+	writeVectorString(&buf, vector.Info.Provider)
+	writeVectorString(&buf, vector.Info.Model)
+	writeVectorString(&buf, vector.Info.TextHash)
+
+	if op == vectorOpPut {
+		writeVectorValues(&buf, vector.Chunks[0].Values)
+		return buf.Bytes()
+	}
+
+	writeVectorUvarint(&buf, uint64(len(vector.Chunks)))
+	for _, chunk := range vector.Chunks {
+		if op == vectorOpPutFields {
+			writeVectorString(&buf, chunk.Field)
+		}
+		writeVectorUvarint(&buf, uint64(chunk.Start))
+		writeVectorUvarint(&buf, uint64(chunk.End))
+		writeVectorValues(&buf, chunk.Values)
+	}
+
+	return buf.Bytes()
+}
+
+func writeVectorValues(buf *bytes.Buffer, values quantize.Vector) {
+	buf.WriteByte(byte(values.Encoding))
+
+	var header [4]byte
+	binary.LittleEndian.PutUint32(header[:], uint32(values.Len()))
+	buf.Write(header[:])
+
+	data := make([]byte, values.Size())
+	switch values.Encoding {
+	case quantize.Float16:
+		for i, value := range values.Float16 {
+			binary.LittleEndian.PutUint16(data[2*i:], value)
+		}
+	case quantize.Int8:
+		binary.LittleEndian.PutUint32(header[:], math.Float32bits(values.Scale))
+		buf.Write(header[:])
+		for i, value := range values.Int8 {
+			data[i] = byte(value)
+		}
+	default:
+		for i, value := range values.Float32 {
+			binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+		}
+	}
+	buf.Write(data)
+}
+
+func encodeVectorDelete(contentType entities.ContentType, id string) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(vectorOpDelete)
+	writeVectorString(&buf, string(contentType))
+	writeVectorString(&buf, id)
+
+	return buf.Bytes()
+}
+
+func decodeVector(r *bytes.Reader, op byte) (Vector, error) {
+	var vector Vector
+	var err error
+
+	if vector.Info.Provider, err = readVectorString(r); err != nil {
+		return vector, err
+	}
+	if vector.Info.Model, err = readVectorString(r); err != nil {
+		return vector, err
+	}
+	if vector.Info.TextHash, err = readVectorString(r); err != nil {
+		return vector, err
+	}
+
+	if op == vectorOpPut {
+		values, err := readVectorValues(r)
+		if err != nil {
+			return vector, err
+		}
+		vector.Chunks = []VectorChunk{{Values: values}}
+		vector.Info.Dimensions = values.Len()
+		return vector, nil
+	}
+
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return vector, err
+	}
+	if count > uint64(r.Len()) {
+		return vector, io.ErrUnexpectedEOF
+	}
+
+	vector.Chunks = make([]VectorChunk, count)
+	for i := range vector.Chunks {
+		var field string
+		if op == vectorOpPutFields {
+			if field, err = readVectorString(r); err != nil {
+				return vector, err
+			}
+		}
+		start, err := binary.ReadUvarint(r)
+		if err != nil {
+			return vector, err
+		}
+		end, err := binary.ReadUvarint(r)
+		if err != nil {
+			return vector, err
+		}
+		values, err := readVectorValues(r)
+		if err != nil {
+			return vector, err
+		}
+		if i > 0 && values.Len() != vector.Info.Dimensions {
+			return vector, fmt.Errorf("chunk %d has %d dimensions instead of %d", i, values.Len(), vector.Info.Dimensions)
+		}
+
+		vector.Chunks[i] = VectorChunk{Field: field, Start: int(start), End: int(end), Values: values}
+		vector.Info.Dimensions = values.Len()
+	}
+
+	return vector, nil
+}
+
+func readVectorValues(r *bytes.Reader) (quantize.Vector, error) {
+	var values quantize.Vector
+
+	encoding, err := r.ReadByte()
+	if err != nil {
+		return values, err
+	}
+	values.Encoding = quantize.Encoding(encoding)
+
+	var dimensions uint32
+	if err := binary.Read(r, binary.LittleEndian, &dimensions); err != nil {
+		return values, err
+	}
+
+	switch values.Encoding {
+	case quantize.Float32:
+		if int(dimensions)*4 > r.Len() {
+			return values, io.ErrUnexpectedEOF
+		}
+		values.Float32 = make([]float32, dimensions)
+		err = binary.Read(r, binary.LittleEndian, values.Float32)
+	case quantize.Float16:
+		if int(dimensions)*2 > r.Len() {
+			return values, io.ErrUnexpectedEOF
+		}
+		values.Float16 = make([]uint16, dimensions)
+		err = binary.Read(r, binary.LittleEndian, values.Float16)
+	case quantize.Int8:
+		if err := binary.Read(r, binary.LittleEndian, &values.Scale); err != nil {
+			return values, err
+		}
+		if int(dimensions) > r.Len() {
+			return values, io.ErrUnexpectedEOF
+		}
+		values.Int8 = make([]int8, dimensions)
+		err = binary.Read(r, binary.LittleEndian, values.Int8)
+	default:
+		return values, fmt.Errorf("unknown vector encoding %d", encoding)
+	}
+
+	return values, err
+}
+
+func writeVectorUvarint(w *bytes.Buffer, n uint64) {
+	var size [binary.MaxVarintLen64]byte
+	w.Write(size[:binary.PutUvarint(size[:], n)])
+}
+
+func writeVectorString(w *bytes.Buffer, s string) {
+	writeVectorUvarint(w, uint64(len(s)))
+	w.WriteString(s)
+}
+
+func readVectorString(r *bytes.Reader) (string, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if size > uint64(r.Len()) {
+		return "", io.ErrUnexpectedEOF
+	}
+
+	s := make([]byte, size)
+	if _, err := io.ReadFull(r, s); err != nil {
+		return "", err
+	}
+
+	return string(s), nil
+}
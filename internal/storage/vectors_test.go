@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"MMDContent/internal/entities"
+	"MMDContent/internal/quantize"
+)
+
+func testVector(encoding quantize.Encoding, chunks ...VectorChunk) Vector {
+	for i := range chunks {
+		chunks[i].Values = quantize.Encode([]float32{0.5, -0.25, 1}, encoding)
+	}
+
+	return Vector{
+		Info: entities.EmbeddingInfo{
+			Provider:   "openai",
+			Model:      "text-embedding-3-large",
+			Dimensions: 3,
+			TextHash:   "hash",
+		},
+		Chunks: chunks,
+	}
+}
+
+func TestVectorRecordRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		op     byte
+		vector Vector
+	}{
+		{"put", vectorOpPut, testVector(quantize.Float32, VectorChunk{})},
+		{"put float16", vectorOpPut, testVector(quantize.Float16, VectorChunk{})},
+		{"put int8", vectorOpPut, testVector(quantize.Int8, VectorChunk{})},
+		{"put chunks", vectorOpPutChunks, testVector(quantize.Float32,
+			VectorChunk{Start: 0, End: 120},
+			VectorChunk{Start: 100, End: 240},
+		)},
+		{"put fields", vectorOpPutFields, testVector(quantize.Float32,
+			VectorChunk{Field: entities.EmbeddingFieldName},
+			VectorChunk{Field: entities.EmbeddingFieldDescription, Start: 0, End: 80},
+			VectorChunk{Field: entities.EmbeddingFieldPath},
+		)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeVectorRecord(&buf, encodeVectorPut(entities.ContentTypeModel, "item", tt.vector))
+
+			payload, size, err := readVectorRecord(buf.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size != buf.Len() {
+				t.Errorf("record size = %d, want %d", size, buf.Len())
+			}
+			if payload[0] != tt.op {
+				t.Errorf("op = %d, want %d", payload[0], tt.op)
+			}
+
+			v := &Vectors{
+				encoding: tt.vector.Chunks[0].Values.Encoding,
+				data:     make(map[entities.ContentType]map[string]Vector),
+			}
+			if err := v.apply(payload); err != nil {
+				t.Fatal(err)
+			}
+
+			got := v.data[entities.ContentTypeModel]["item"]
+			if !reflect.DeepEqual(got, tt.vector) {
+				t.Errorf("decoded %+v, want %+v", got, tt.vector)
+			}
+		})
+	}
+}
+
+func TestVectorRecordDelete(t *testing.T) {
+	v := &Vectors{
+		encoding: quantize.Float32,
+		data:     make(map[entities.ContentType]map[string]Vector),
+	}
+	v.items(entities.ContentTypeStage)["item"] = testVector(quantize.Float32, VectorChunk{})
+
+	var buf bytes.Buffer
+	writeVectorRecord(&buf, encodeVectorDelete(entities.ContentTypeStage, "item"))
+
+	payload, _, err := readVectorRecord(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload[0] != vectorOpDelete {
+		t.Errorf("op = %d, want %d", payload[0], vectorOpDelete)
+	}
+	if err := v.apply(payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := v.data[entities.ContentTypeStage]["item"]; ok {
+		t.Error("vector still stored after delete")
+	}
+}
+
+// writeTestVectors stores three vectors, then deletes one, and returns the file
+func writeTestVectors(t *testing.T) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "vectors.bin")
+	v, err := NewVectorsLoaded(filename, quantize.Float32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	embedding := entities.Embedding{
+		Chunks: []entities.EmbeddingChunk{{Vector: []float64{0.5, -0.25, 1}}},
+		Info:   entities.EmbeddingInfo{Provider: "openai", Model: "model", Dimensions: 3},
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if err := v.Put(entities.ContentTypeModel, map[string]entities.Embedding{id: embedding}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := v.Delete(entities.ContentTypeModel, "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	return filename
+}
+
+// vectorRecordOffsets returns where each record of a vector file starts
+func vectorRecordOffsets(t *testing.T, content []byte) []int {
+	t.Helper()
+
+	var offsets []int
+	for offset := len(vectorsHeader); offset < len(content); {
+		_, size, err := readVectorRecord(content[offset:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		offsets = append(offsets, offset)
+		offset += size
+	}
+
+	return offsets
+}
+
+func loadedIDs(t *testing.T, filename string) []string {
+	t.Helper()
+
+	v, err := NewVectorsLoaded(filename, quantize.Float32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	var ids []string
+	for _, id := range []string{"a", "b", "c"} {
+		if _, ok := v.Get(entities.ContentTypeModel, id); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func TestVectorsReload(t *testing.T) {
+	filename := writeTestVectors(t)
+
+	if ids := loadedIDs(t, filename); !reflect.DeepEqual(ids, []string{"a", "c"}) {
+		t.Errorf("loaded %v, want [a c]", ids)
+	}
+}
+
+func TestVectorsDropPartialRecord(t *testing.T) {
+	filename := writeTestVectors(t)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash in the middle of an append leaves the start of a record
+	var buf bytes.Buffer
+	writeVectorRecord(&buf, encodeVectorDelete(entities.ContentTypeModel, "a"))
+	torn := append(bytes.Clone(content), buf.Bytes()[:buf.Len()-3]...)
+	if err := os.WriteFile(filename, torn, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := loadedIDs(t, filename); !reflect.DeepEqual(ids, []string{"a", "c"}) {
+		t.Errorf("loaded %v, want [a c]", ids)
+	}
+
+	truncated, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(truncated, content) {
+		t.Errorf("file is %d bytes after load, want the %d bytes before the partial record", len(truncated), len(content))
+	}
+}
+
+func TestVectorsDropTornLastRecord(t *testing.T) {
+	filename := writeTestVectors(t)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last record, deleting b, has the right length but not all of its bytes
+	offsets := vectorRecordOffsets(t, content)
+	last := offsets[len(offsets)-1]
+	content[last+6] ^= 0xff
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := loadedIDs(t, filename); !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+		t.Errorf("loaded %v, want [a b c]", ids)
+	}
+}
+
+func TestVectorsRecoverCorruptRecord(t *testing.T) {
+	filename := writeTestVectors(t)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second record, storing b, is corrupt while the ones after it are fine
+	offsets := vectorRecordOffsets(t, content)
+	content[offsets[1]+6] ^= 0xff
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewVectorsLoaded(filename, quantize.Float32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovery, ok := v.Startup()
+	v.Close()
+	if !ok {
+		t.Fatal("corrupt file not reported")
+	}
+
+	copied, err := os.ReadFile(recovery.CorruptFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(copied, content) {
+		t.Errorf("copy %s does not hold the corrupt file", recovery.CorruptFile)
+	}
+
+	// The records before the corrupt one start a fresh log
+	if ids := loadedIDs(t, filename); !reflect.DeepEqual(ids, []string{"a"}) {
+		t.Errorf("loaded %v, want [a]", ids)
+	}
+	rewritten, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records := vectorRecordOffsets(t, rewritten); len(records) != 1 {
+		t.Errorf("rewritten file holds %d records, want 1", len(records))
+	}
+}
@@ -6,13 +6,18 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
 
+	"MMDContent/internal/entities"
 	"MMDContent/internal/handlers"
-	"MMDContent/internal/services/openai"
+	"MMDContent/internal/quantize"
+	"MMDContent/internal/search"
+	"MMDContent/internal/services/embedding"
 	"MMDContent/internal/storage"
 )
 
@@ -23,32 +28,144 @@ var assets embed.FS
 var icon []byte
 
 func main() {
-	client := openai.NewClient(os.Getenv("OPENAI_API_KEY"))
-	modelsStorage, err := storage.NewModelsLoaded(filepath.Join("data", "Models"), filepath.Join("data", "models.json"))
+	backups := storage.NewBackups(filepath.Join("data", "backups"), envInt("CATALOG_BACKUPS", storage.DefaultBackups))
+
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		err := restore(backups, os.Args[2:])
+		if err != nil {
+			slog.Error("error restoring backup", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Everything is kept in data, a first launch starts without it
+	err := os.MkdirAll("data", 0755)
+	if err != nil {
+		slog.Error("error creating data folder", "error", err)
+		return
+	}
+
+	usageStorage, err := storage.NewUsageLoaded(filepath.Join("data", "usage.json"))
+	if err != nil {
+		slog.Error("error loading embedding usage", "error", err)
+		return
+	}
+	meter := handlers.NewUsageMeter(
+		usageStorage,
+		envFloat("EMBEDDING_MONTHLY_BUDGET", 0),
+		envFloat("EMBEDDING_PRICE_PER_MILLION_TOKENS", 0),
+	)
+
+	provider, err := embedding.NewProvider(embedding.Config{
+		Provider:   os.Getenv("EMBEDDING_PROVIDER"),
+		BaseURL:    os.Getenv("EMBEDDING_BASE_URL"),
+		Model:      os.Getenv("EMBEDDING_MODEL"),
+		APIKey:     os.Getenv("OPENAI_API_KEY"),
+		Dimensions: envInt("EMBEDDING_DIMENSIONS", 0),
+		Timeout:    envDuration("EMBEDDING_TIMEOUT", 0),
+		MaxRetries: envInt("EMBEDDING_MAX_RETRIES", 0),
+		OnUsage:    meter.Record,
+	})
+	if err != nil {
+		slog.Error("error configuring embedding provider", "error", err)
+		return
+	}
+	if provider == nil {
+		slog.Info("no embedding provider configured, search uses keywords only")
+	}
+
+	encoding, err := quantize.ParseEncoding(os.Getenv("VECTOR_ENCODING"))
+	if err != nil {
+		slog.Error("error configuring vector encoding", "error", err)
+		return
+	}
+
+	vectors, err := storage.NewVectorsLoaded(filepath.Join("data", "vectors.bin"), encoding)
+	if err != nil {
+		slog.Error("error loading vectors", "error", err)
+		return
+	}
+
+	index, err := search.NewVectorIndexLoaded(
+		filepath.Join("data", "index"),
+		vectors,
+		func(info entities.EmbeddingInfo) bool {
+			return provider != nil && embedding.SameSpace(info, provider)
+		},
+		search.FieldWeights{
+			Name:        envFloat("SEARCH_NAME_WEIGHT", search.DefaultFieldWeights.Name),
+			Description: envFloat("SEARCH_DESCRIPTION_WEIGHT", search.DefaultFieldWeights.Description),
+			Path:        envFloat("SEARCH_PATH_WEIGHT", search.DefaultFieldWeights.Path),
+		},
+		entities.ContentTypeModel,
+		entities.ContentTypeStage,
+		entities.ContentTypeMotion,
+	)
+	if err != nil {
+		slog.Error("error loading vector index", "error", err)
+		return
+	}
+
+	modelsStorage, err := storage.NewModelsLoaded(filepath.Join("data", "Models"), catalogFilename("models"), vectors, backups)
 	if err != nil {
 		slog.Error("error loading models", "error", err)
 		return
 	}
 
-	stagesStorage, err := storage.NewStagesLoaded(filepath.Join("data", "Stages"), filepath.Join("data", "stages.json"))
+	stagesStorage, err := storage.NewStagesLoaded(filepath.Join("data", "Stages"), catalogFilename("stages"), vectors, backups)
 	if err != nil {
 		slog.Error("error loading stages", "error", err)
 		return
 	}
 
-	motionsStorage, err := storage.NewMotionsLoaded(filepath.Join("data", "Motions"), filepath.Join("data", "motions.json"))
+	motionsStorage, err := storage.NewMotionsLoaded(filepath.Join("data", "Motions"), catalogFilename("motions"), vectors, backups)
 	if err != nil {
 		slog.Error("error loading motions", "error", err)
 		return
 	}
 
-	images := handlers.NewImages()
-	embeddings := handlers.NewEmbeddings(*client)
-	models := handlers.NewModels(*client, modelsStorage)
-	stages := handlers.NewStages(*client, stagesStorage)
-	motions := handlers.NewMotions(*client, motionsStorage)
+	queryEmbeddings, err := storage.NewQueryEmbeddingsLoaded(
+		filepath.Join("data", "query_embeddings.bin"),
+		envInt("QUERY_CACHE_SIZE", storage.DefaultQueryEmbeddingsCapacity),
+	)
+	if err != nil {
+		slog.Error("error loading query embedding cache", "error", err)
+		return
+	}
 
-	app := NewApp(modelsStorage, stagesStorage)
+	// Searches reuse the embeddings of queries typed before
+	searchProvider := provider
+	if provider != nil {
+		searchProvider = embedding.NewCached(provider, queryEmbeddings)
+	}
+
+	keywords := search.NewKeywordIndex(modelsStorage, stagesStorage, motionsStorage)
+	weights := search.HybridWeights{
+		Keyword:  envFloat("SEARCH_KEYWORD_WEIGHT", search.DefaultHybridWeights.Keyword),
+		Semantic: envFloat("SEARCH_SEMANTIC_WEIGHT", search.DefaultHybridWeights.Semantic),
+	}
+
+	app := NewApp(modelsStorage, stagesStorage, motionsStorage, vectors, usageStorage, index, queryEmbeddings)
+
+	images := handlers.NewImages()
+	usage := handlers.NewUsage(meter)
+	embeddings := handlers.NewEmbeddings(
+		provider,
+		app.emit,
+		storage.NewEmbeddingRuns(filepath.Join("data", "embeddings_run.json")),
+		vectors,
+		meter,
+		handlers.EmbeddingFields(os.Getenv("EMBEDDING_FIELDS")),
+		modelsStorage,
+		stagesStorage,
+		motionsStorage,
+	)
+	models := handlers.NewModels(searchProvider, index, keywords, weights, modelsStorage)
+	stages := handlers.NewStages(searchProvider, index, keywords, weights, stagesStorage)
+	motions := handlers.NewMotions(searchProvider, index, keywords, weights, motionsStorage)
+	catalog := handlers.NewCatalog(searchProvider, models, stages, motions)
+	catalogBackups := handlers.NewBackups(modelsStorage, stagesStorage, motionsStorage)
 
 	err = wails.Run(&options.App{
 		Title:            "MMDContent",
@@ -64,9 +181,12 @@ func main() {
 			app,
 			images,
 			embeddings,
+			usage,
 			models,
 			stages,
 			motions,
+			catalog,
+			catalogBackups,
 		},
 	})
 
@@ -74,3 +194,33 @@ func main() {
 		log.Fatal("Error starting app:", err)
 	}
 }
+
+// envInt reads an integer environment variable, falling back to def when unset or invalid
+func envInt(name string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// envFloat reads a decimal environment variable, falling back to def when unset or invalid
+func envFloat(name string, def float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// envDuration reads a duration (e.g. "30s") environment variable, falling back to def when unset or invalid
+func envDuration(name string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+
+	return value
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"MMDContent/internal/storage"
+)
+
+// catalogs are the catalog files that are backed up, in data/<name>.json
+var catalogs = []string{"models", "stages", "motions"}
+
+// restore runs the restore command:
+//
+//	MMDContent restore                  lists the backups of every catalog
+//	MMDContent restore <catalog>        restores the newest backup of models, stages or motions
+//	MMDContent restore <catalog> <name> restores the backup with that name
+//
+// It prints to the console, so it only works from a console build: the Windows GUI build
+// has none. The app itself lists and restores backups with handlers.Backups.
+func restore(backups *storage.Backups, args []string) error {
+	if len(args) == 0 {
+		for _, catalog := range catalogs {
+			list, err := backups.List(catalogFilename(catalog))
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s: %d backups\n", catalog, len(list))
+			for _, backup := range list {
+				fmt.Printf("  %s  %s  %d bytes\n", backup.Name, backup.Time.Format("2006-01-02 15:04:05"), backup.Size)
+			}
+		}
+		return nil
+	}
+
+	catalog := args[0]
+	if !slices.Contains(catalogs, catalog) {
+		return fmt.Errorf("unknown catalog %q, expected one of %v", catalog, catalogs)
+	}
+
+	name := ""
+	if len(args) > 1 {
+		name = args[1]
+	}
+
+	backup, err := backups.Restore(catalogFilename(catalog), name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("restored %s from %s\n", catalog, backup.Name)
+	return nil
+}
+
+func catalogFilename(catalog string) string {
+	return filepath.Join("data", catalog+".json")
+}